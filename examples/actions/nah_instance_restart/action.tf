@@ -0,0 +1,17 @@
+# Restart the instance whenever its configuration version changes
+action "nah_instance_restart" "web" {
+  config {
+    instance_id = nah_instance.web.id
+  }
+}
+
+resource "terraform_data" "web_config" {
+  input = var.web_config_version
+
+  lifecycle {
+    action_trigger {
+      events  = [after_update]
+      actions = [action.nah_instance_restart.web]
+    }
+  }
+}
@@ -0,0 +1,7 @@
+# Start the instance on demand with:
+#   terraform apply -invoke=action.nah_instance_start.web
+action "nah_instance_start" "web" {
+  config {
+    instance_id = nah_instance.web.id
+  }
+}
@@ -0,0 +1,11 @@
+# Stop the instance on demand with:
+#   terraform apply -invoke=action.nah_instance_stop.web
+action "nah_instance_stop" "web" {
+  config {
+    instance_id = nah_instance.web.id
+
+    timeouts {
+      invoke = "5m"
+    }
+  }
+}
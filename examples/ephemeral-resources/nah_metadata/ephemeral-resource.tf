@@ -0,0 +1,8 @@
+# Read a secret stored in NahCloud without writing it to the state
+ephemeral "nah_metadata" "db_password" {
+  id = var.db_password_metadata_id
+}
+
+provider "postgresql" {
+  password = ephemeral.nah_metadata.db_password.value
+}
@@ -0,0 +1,9 @@
+# Read a credentials file stored in NahCloud without writing it to the state
+ephemeral "nah_object" "credentials" {
+  bucket_id = var.bucket_id
+  id        = var.credentials_object_id
+}
+
+provider "google" {
+  credentials = provider::nah::decode_content(ephemeral.nah_object.credentials.content)
+}
@@ -0,0 +1,11 @@
+# A read-only token for a second provider configuration, valid only for the
+# duration of the run
+ephemeral "nah_token" "readonly" {
+  scopes = ["read"]
+  ttl    = "15m"
+}
+
+provider "nah" {
+  alias = "readonly"
+  token = ephemeral.nah_token.readonly.token
+}
@@ -0,0 +1,8 @@
+data "nah_object" "config" {
+  bucket_id = nah_bucket.assets.id
+  id        = var.object_id
+}
+
+output "settings" {
+  value = jsondecode(provider::nah::decode_content(data.nah_object.config.content))
+}
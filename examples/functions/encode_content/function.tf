@@ -0,0 +1,5 @@
+resource "nah_object" "readme" {
+  bucket_id = nah_bucket.assets.id
+  path      = "README.txt"
+  content   = provider::nah::encode_content("Hello, World!")
+}
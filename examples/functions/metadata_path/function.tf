@@ -0,0 +1,5 @@
+resource "nah_metadata" "setting" {
+  # "/config/my-app/log_level"
+  path  = provider::nah::metadata_path("config/", var.app_name, "log_level")
+  value = "info"
+}
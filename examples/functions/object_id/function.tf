@@ -0,0 +1,5 @@
+# The ID to import an object with, e.g. in an import block
+import {
+  to = nah_object.config
+  id = provider::nah::object_id(var.bucket_id, var.object_id)
+}
@@ -0,0 +1,7 @@
+locals {
+  object = provider::nah::parse_object_id("bkt-1234/obj-5678")
+}
+
+output "bucket_id" {
+  value = local.object.bucket_id # "bkt-1234"
+}
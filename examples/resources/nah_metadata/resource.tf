@@ -8,3 +8,15 @@ resource "nah_metadata" "db_config" {
   path  = "/config/database/host"
   value = "localhost:5432"
 }
+
+# Store a secret without writing it to the state (Terraform 1.11+). Bump
+# value_wo_version to send a new value.
+ephemeral "random_password" "db" {
+  length = 32
+}
+
+resource "nah_metadata" "db_password" {
+  path             = "/config/database/password"
+  value_wo         = ephemeral.random_password.db.result
+  value_wo_version = 1
+}
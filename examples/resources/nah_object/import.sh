@@ -0,0 +1,2 @@
+# Objects are imported using the bucket ID and the object ID separated by a slash.
+terraform import nah_object.config bkt-0123456789ab/obj-0123456789ab
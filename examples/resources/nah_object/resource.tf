@@ -13,6 +13,15 @@ resource "nah_object" "config" {
   }))
 }
 
+# Store a credentials file without writing it to the state (Terraform 1.11+).
+# Bump content_wo_version to send new content.
+resource "nah_object" "credentials" {
+  bucket_id          = nah_bucket.assets.id
+  path               = "secrets/credentials.json"
+  content_wo         = base64encode(var.credentials_json)
+  content_wo_version = 1
+}
+
 output "object_id" {
   value = nah_object.config.id
 }
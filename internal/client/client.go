@@ -3,34 +3,276 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hypertf/terraform-provider-nah/internal/nahcloud"
 )
 
 const DefaultEndpoint = "https://nahcloud.com"
 
+// DefaultUserAgent is the User-Agent sent when none is configured.
+const DefaultUserAgent = "terraform-provider-nah"
+
+// DefaultResponseHeaderTimeout is how long the client waits for the server
+// to start responding to a request whose context has no deadline.
+const DefaultResponseHeaderTimeout = 30 * time.Second
+
 // Client is the NahCloud API client.
 type Client struct {
-	endpoint   string
-	token      string
-	httpClient *http.Client
+	endpoint    string
+	token       string
+	userAgent   string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+
+	responseHeaderTimeout time.Duration
+	requestsPerSecond     float64
+	maxConcurrentRequests int
+	logMaxBodySize        int
+	tlsConfig             *tls.Config
+	proxyURL              *url.URL
+	headers               http.Header
+	sensitiveHeaders      []string
+	faultInjection        *FaultInjection
+	vcrMode               VCRMode
+	vcrCassette           string
+	pollInterval          time.Duration
+	maxPollInterval       time.Duration
+}
+
+// Option configures optional behavior of a Client.
+type Option func(*Client)
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry transient failures.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithResponseHeaderTimeout sets how long the client waits for the server to
+// start responding to each attempt of a request whose context has no
+// deadline. Attempts that time out are retried like connection errors. A
+// value of zero or less waits indefinitely.
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.responseHeaderTimeout = d
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests per second.
+// A value of zero or less disables rate limiting.
+func WithRateLimit(requestsPerSecond float64) Option {
+	return func(c *Client) {
+		c.requestsPerSecond = requestsPerSecond
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of requests in flight at once.
+// A value of zero or less means no limit.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(c *Client) {
+		c.maxConcurrentRequests = n
+	}
+}
+
+// WithLogMaxBodySize sets how many bytes of each request and response body
+// are included in HTTP trace logs.
+func WithLogMaxBodySize(n int) Option {
+	return func(c *Client) {
+		c.logMaxBodySize = n
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the endpoint.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithProxyURL routes all requests through the proxy at proxyURL instead of
+// the proxy selected by the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment
+// variables. Credentials in the URL are sent to the proxy.
+func WithProxyURL(proxyURL *url.URL) Option {
+	return func(c *Client) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		c.headers.Set(name, value)
+	}
+}
+
+// WithSensitiveHeader adds a header to every request whose value is masked
+// in logs.
+func WithSensitiveHeader(name, value string) Option {
+	return func(c *Client) {
+		c.headers.Set(name, value)
+		c.sensitiveHeaders = append(c.sensitiveHeaders, name)
+	}
+}
+
+// WithPollInterval sets the delay between the first two polls when waiting
+// for an object to reach a state, and the cap on the delay as it backs off.
+func WithPollInterval(interval, maxInterval time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = interval
+		c.maxPollInterval = maxInterval
+	}
 }
 
 // NewClient creates a new NahCloud API client.
-func NewClient(endpoint, token string) *Client {
+func NewClient(endpoint, token string, opts ...Option) *Client {
 	if endpoint == "" {
 		endpoint = DefaultEndpoint
 	}
-	return &Client{
-		endpoint: endpoint,
-		token:    token,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+	// Requests are bounded by the deadline of their context, e.g. the
+	// timeouts of a Terraform operation, rather than a fixed timeout. Callers
+	// without a deadline still never wait forever for a server that does not
+	// answer, thanks to the response header timeout, which only applies to
+	// them.
+	c := &Client{
+		endpoint:              trimEndpoint(endpoint),
+		token:                 token,
+		userAgent:             DefaultUserAgent,
+		httpClient:            &http.Client{},
+		retryPolicy:           DefaultRetryPolicy(),
+		responseHeaderTimeout: DefaultResponseHeaderTimeout,
+		logMaxBodySize:        DefaultLogMaxBodySize,
+		headers:               make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.httpClient.Transport = c.transport()
+	return c
+}
+
+// transport builds the round tripper stack used by the HTTP client.
+func (c *Client) transport() http.RoundTripper {
+	proxy := http.ProxyFromEnvironment
+	if c.proxyURL != nil {
+		proxy = http.ProxyURL(c.proxyURL)
+	}
+
+	var base http.RoundTripper = &http.Transport{
+		Proxy:                 proxy,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       c.tlsConfig,
+	}
+
+	// memory://<name> and file://<path> endpoints are served by an
+	// in-process implementation of the API. In-memory data is shared by all
+	// clients using the same name; file-backed data by all processes using
+	// the same file.
+	if u, err := url.Parse(c.endpoint); err == nil {
+		switch u.Scheme {
+		case "memory":
+			base = nahcloud.Transport(nahcloud.Shared(u.Host))
+		case "file":
+			// The file path is part of the request URL; strip it so that
+			// the server sees /v1/... paths.
+			base = nahcloud.Transport(http.StripPrefix(u.Path, nahcloud.NewFileServer(fileURLPath(u))))
+		}
+	}
+
+	base = newHeaderTimeoutTransport(base, c.responseHeaderTimeout)
+
+	secrets := c.secrets()
+
+	// Cassettes capture what the server actually sent, so recording happens
+	// below fault injection. Replaying takes the place of the server.
+	if c.vcrMode != "" {
+		vcr := newVCRTransport(base, c.vcrMode, c.vcrCassette, c.endpoint)
+		vcr.secrets = secrets
+		for _, name := range c.sensitiveHeaders {
+			vcr.sensitiveHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+		base = vcr
+	}
+
+	// Injected faults sit below logging so that they show up in the logs
+	// like real failures.
+	if c.faultInjection != nil {
+		base = newFaultTransport(base, c.faultInjection)
+	}
+
+	logging := newLoggingTransport(base, c.logMaxBodySize)
+	logging.secrets = secrets
+	for _, name := range c.sensitiveHeaders {
+		logging.sensitiveHeaders[http.CanonicalHeaderKey(name)] = true
+	}
+
+	var rt http.RoundTripper = logging
+	if c.requestsPerSecond > 0 || c.maxConcurrentRequests > 0 {
+		rt = newLimitedTransport(rt, c.requestsPerSecond, c.maxConcurrentRequests)
+	}
+	return rt
+}
+
+// secrets returns the credentials that must never be logged or recorded: the
+// token and the values of sensitive headers.
+func (c *Client) secrets() []string {
+	var secrets []string
+	if c.token != "" {
+		secrets = append(secrets, c.token)
+	}
+	for _, name := range c.sensitiveHeaders {
+		if v := c.headers.Get(name); v != "" {
+			secrets = append(secrets, v)
+		}
 	}
+	return secrets
+}
+
+// trimEndpoint removes a trailing slash from the path of endpoint, as API
+// paths are appended to it. An endpoint without a path, such as memory://,
+// is left as is: trimming it would turn the first path segment of every
+// request into the host.
+func trimEndpoint(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Path == "" {
+		return endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+// fileURLPath returns the local path of a file:// URL. Relative paths such
+// as file://nah-state.json are resolved against the working directory.
+func fileURLPath(u *url.URL) string {
+	p := u.Host + u.Path
+	if runtime.GOOS == "windows" && len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		// file:///C:/path
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
 }
 
 // Project represents a NahCloud project.
@@ -39,6 +281,9 @@ type Project struct {
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// ETag identifies this version of the resource for conditional requests.
+	ETag string `json:"-"`
 }
 
 // Instance represents a NahCloud compute instance.
@@ -52,6 +297,9 @@ type Instance struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// ETag identifies this version of the resource for conditional requests.
+	ETag string `json:"-"`
 }
 
 // Metadata represents NahCloud key-value metadata.
@@ -61,6 +309,9 @@ type Metadata struct {
 	Value     string    `json:"value"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// ETag identifies this version of the resource for conditional requests.
+	ETag string `json:"-"`
 }
 
 // Bucket represents a NahCloud storage bucket.
@@ -69,6 +320,9 @@ type Bucket struct {
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// ETag identifies this version of the resource for conditional requests.
+	ETag string `json:"-"`
 }
 
 // Object represents a NahCloud storage object.
@@ -79,29 +333,172 @@ type Object struct {
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// ETag identifies this version of the resource for conditional requests.
+	ETag string `json:"-"`
+}
+
+// RequestOption customizes a single API request.
+type RequestOption func(*http.Request)
+
+// IfMatch makes an update or delete conditional on the object still having
+// the given ETag. The API responds with 412 Precondition Failed if the
+// object was modified in the meantime. An empty etag sends no condition.
+func IfMatch(etag string) RequestOption {
+	return func(req *http.Request) {
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+	}
+}
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyKey sets the key identifying a logical create operation. The
+// server executes requests sharing a key at most once and replays the
+// original response to repeats. Create methods generate a key per call when
+// none is given, and retries of that call reuse it.
+func IdempotencyKey(key string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+}
+
+// withIdempotencyKey prepends a freshly generated idempotency key to opts, so
+// that a key passed by the caller takes precedence.
+func withIdempotencyKey(opts []RequestOption) []RequestOption {
+	return append([]RequestOption{IdempotencyKey(rand.Text())}, opts...)
 }
 
-func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, opts ...RequestOption) (*http.Response, error) {
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	// applied records whether an earlier attempt may have been acted on by
+	// the server even though it failed.
+	applied := false
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if jsonBody != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		for name, values := range c.headers {
+			req.Header[name] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		for _, opt := range opts {
+			opt(req)
+		}
+
+		idempotent := method != http.MethodPost || req.Header.Get(idempotencyKeyHeader) != ""
+
+		resp, err := c.httpClient.Do(req)
+		if applied && err == nil && resp.StatusCode == http.StatusPreconditionFailed &&
+			method == http.MethodPatch && req.Header.Get("If-Match") != "" {
+			return c.resolvePreconditionFailed(ctx, path, jsonBody, resp)
+		}
+		if attempt >= c.retryPolicy.MaxAttempts || ctx.Err() != nil || !c.retryPolicy.retryable(idempotent, resp, err) {
+			if resp != nil && resp.Header.Get(idempotentReplayedHeader) == "true" {
+				tflog.Info(ctx, "NahCloud replayed the response of an earlier attempt with the same idempotency key", map[string]interface{}{
+					"method": method,
+					"path":   path,
+				})
+			}
+			return resp, err
+		}
+
+		applied = applied || mayHaveApplied(resp, err)
+		delay := c.retryPolicy.delay(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		tflog.Debug(ctx, "Retrying NahCloud request", map[string]interface{}{
+			"method":  method,
+			"path":    path,
+			"attempt": attempt,
+			"delay":   delay.String(),
+			"error":   retryReason(resp, err),
+		})
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resolvePreconditionFailed handles a 412 response to a retried conditional
+// update. When an earlier attempt was applied but its response was lost, the
+// retry carries an ETag that attempt made stale. The object is read back: if
+// it already has the values that were sent, the read stands in for the lost
+// response; otherwise the 412 stands. The 412 body is read and closed first,
+// as an open body holds a concurrency slot the read may need.
+func (c *Client) resolvePreconditionFailed(ctx context.Context, path string, jsonBody []byte, resp *http.Response) (*http.Response, error) {
+	failed, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(failed))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+	current, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return resp, nil
+	}
+	body, err := io.ReadAll(current.Body)
+	current.Body.Close()
+	if err != nil || current.StatusCode != http.StatusOK || !jsonFieldsMatch(jsonBody, body) {
+		return resp, nil
 	}
 
-	return c.httpClient.Do(req)
+	tflog.Info(ctx, "NahCloud already applied an earlier attempt of a conditional update whose response was lost", map[string]interface{}{
+		"method": http.MethodPatch,
+		"path":   path,
+	})
+	current.Body = io.NopCloser(bytes.NewReader(body))
+	return current, nil
+}
+
+// jsonFieldsMatch reports whether every field of the JSON object sent has
+// the same value in the JSON object current.
+func jsonFieldsMatch(sent, current []byte) bool {
+	var want, got map[string]interface{}
+	if json.Unmarshal(sent, &want) != nil || json.Unmarshal(current, &got) != nil {
+		return false
+	}
+	for k, v := range want {
+		if !reflect.DeepEqual(got[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
 }
 
 func handleResponse(resp *http.Response, result interface{}) error {
@@ -113,7 +510,7 @@ func handleResponse(resp *http.Response, result interface{}) error {
 	}
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	if result != nil && len(body) > 0 {
@@ -127,8 +524,8 @@ func handleResponse(resp *http.Response, result interface{}) error {
 
 // Project methods
 
-func (c *Client) CreateProject(ctx context.Context, name string) (*Project, error) {
-	resp, err := c.doRequest(ctx, "POST", "/v1/projects", map[string]string{"name": name})
+func (c *Client) CreateProject(ctx context.Context, name string, opts ...RequestOption) (*Project, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/projects", map[string]string{"name": name}, withIdempotencyKey(opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +533,7 @@ func (c *Client) CreateProject(ctx context.Context, name string) (*Project, erro
 	if err := handleResponse(resp, &project); err != nil {
 		return nil, err
 	}
+	project.ETag = resp.Header.Get("ETag")
 	return &project, nil
 }
 
@@ -148,11 +546,12 @@ func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
 	if err := handleResponse(resp, &project); err != nil {
 		return nil, err
 	}
+	project.ETag = resp.Header.Get("ETag")
 	return &project, nil
 }
 
-func (c *Client) UpdateProject(ctx context.Context, id, name string) (*Project, error) {
-	resp, err := c.doRequest(ctx, "PATCH", "/v1/projects/"+id, map[string]string{"name": name})
+func (c *Client) UpdateProject(ctx context.Context, id, name string, opts ...RequestOption) (*Project, error) {
+	resp, err := c.doRequest(ctx, "PATCH", "/v1/projects/"+id, map[string]string{"name": name}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -160,17 +559,36 @@ func (c *Client) UpdateProject(ctx context.Context, id, name string) (*Project,
 	if err := handleResponse(resp, &project); err != nil {
 		return nil, err
 	}
+	project.ETag = resp.Header.Get("ETag")
 	return &project, nil
 }
 
-func (c *Client) DeleteProject(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/v1/projects/"+id, nil)
+func (c *Client) DeleteProject(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/v1/projects/"+id, nil, opts...)
 	if err != nil {
 		return err
 	}
 	return handleResponse(resp, nil)
 }
 
+// ListProjects returns a single page of projects.
+func (c *Client) ListProjects(ctx context.Context, opts *ListOptions) (*Page[Project], error) {
+	resp, err := c.doRequest(ctx, "GET", listPath("/v1/projects", opts, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	var page Page[Project]
+	if err := handleResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllProjects iterates over every project, fetching pages as needed.
+func (c *Client) AllProjects(ctx context.Context) iter.Seq2[*Project, error] {
+	return listAll(ctx, c.ListProjects)
+}
+
 // Instance methods
 
 type CreateInstanceRequest struct {
@@ -190,8 +608,8 @@ type UpdateInstanceRequest struct {
 	Status   *string `json:"status,omitempty"`
 }
 
-func (c *Client) CreateInstance(ctx context.Context, req *CreateInstanceRequest) (*Instance, error) {
-	resp, err := c.doRequest(ctx, "POST", "/v1/instances", req)
+func (c *Client) CreateInstance(ctx context.Context, req *CreateInstanceRequest, opts ...RequestOption) (*Instance, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/instances", req, withIdempotencyKey(opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +617,7 @@ func (c *Client) CreateInstance(ctx context.Context, req *CreateInstanceRequest)
 	if err := handleResponse(resp, &instance); err != nil {
 		return nil, err
 	}
+	instance.ETag = resp.Header.Get("ETag")
 	return &instance, nil
 }
 
@@ -211,11 +630,12 @@ func (c *Client) GetInstance(ctx context.Context, id string) (*Instance, error)
 	if err := handleResponse(resp, &instance); err != nil {
 		return nil, err
 	}
+	instance.ETag = resp.Header.Get("ETag")
 	return &instance, nil
 }
 
-func (c *Client) UpdateInstance(ctx context.Context, id string, req *UpdateInstanceRequest) (*Instance, error) {
-	resp, err := c.doRequest(ctx, "PATCH", "/v1/instances/"+id, req)
+func (c *Client) UpdateInstance(ctx context.Context, id string, req *UpdateInstanceRequest, opts ...RequestOption) (*Instance, error) {
+	resp, err := c.doRequest(ctx, "PATCH", "/v1/instances/"+id, req, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -223,21 +643,44 @@ func (c *Client) UpdateInstance(ctx context.Context, id string, req *UpdateInsta
 	if err := handleResponse(resp, &instance); err != nil {
 		return nil, err
 	}
+	instance.ETag = resp.Header.Get("ETag")
 	return &instance, nil
 }
 
-func (c *Client) DeleteInstance(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/v1/instances/"+id, nil)
+func (c *Client) DeleteInstance(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/v1/instances/"+id, nil, opts...)
 	if err != nil {
 		return err
 	}
 	return handleResponse(resp, nil)
 }
 
+// ListInstances returns a single page of instances. A non-empty projectID
+// restricts the results to instances of that project.
+func (c *Client) ListInstances(ctx context.Context, projectID string, opts *ListOptions) (*Page[Instance], error) {
+	resp, err := c.doRequest(ctx, "GET", listPath("/v1/instances", opts, url.Values{"project_id": {projectID}}), nil)
+	if err != nil {
+		return nil, err
+	}
+	var page Page[Instance]
+	if err := handleResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllInstances iterates over every instance, optionally restricted to
+// projectID, fetching pages as needed.
+func (c *Client) AllInstances(ctx context.Context, projectID string) iter.Seq2[*Instance, error] {
+	return listAll(ctx, func(ctx context.Context, opts *ListOptions) (*Page[Instance], error) {
+		return c.ListInstances(ctx, projectID, opts)
+	})
+}
+
 // Metadata methods
 
-func (c *Client) CreateMetadata(ctx context.Context, path, value string) (*Metadata, error) {
-	resp, err := c.doRequest(ctx, "POST", "/v1/metadata", map[string]string{"path": path, "value": value})
+func (c *Client) CreateMetadata(ctx context.Context, path, value string, opts ...RequestOption) (*Metadata, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/metadata", map[string]string{"path": path, "value": value}, withIdempotencyKey(opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -245,6 +688,7 @@ func (c *Client) CreateMetadata(ctx context.Context, path, value string) (*Metad
 	if err := handleResponse(resp, &metadata); err != nil {
 		return nil, err
 	}
+	metadata.ETag = resp.Header.Get("ETag")
 	return &metadata, nil
 }
 
@@ -257,6 +701,7 @@ func (c *Client) GetMetadata(ctx context.Context, id string) (*Metadata, error)
 	if err := handleResponse(resp, &metadata); err != nil {
 		return nil, err
 	}
+	metadata.ETag = resp.Header.Get("ETag")
 	return &metadata, nil
 }
 
@@ -265,8 +710,8 @@ type UpdateMetadataRequest struct {
 	Value *string `json:"value,omitempty"`
 }
 
-func (c *Client) UpdateMetadata(ctx context.Context, id string, req *UpdateMetadataRequest) (*Metadata, error) {
-	resp, err := c.doRequest(ctx, "PATCH", "/v1/metadata/"+id, req)
+func (c *Client) UpdateMetadata(ctx context.Context, id string, req *UpdateMetadataRequest, opts ...RequestOption) (*Metadata, error) {
+	resp, err := c.doRequest(ctx, "PATCH", "/v1/metadata/"+id, req, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -274,21 +719,44 @@ func (c *Client) UpdateMetadata(ctx context.Context, id string, req *UpdateMetad
 	if err := handleResponse(resp, &metadata); err != nil {
 		return nil, err
 	}
+	metadata.ETag = resp.Header.Get("ETag")
 	return &metadata, nil
 }
 
-func (c *Client) DeleteMetadata(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/v1/metadata/"+id, nil)
+func (c *Client) DeleteMetadata(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/v1/metadata/"+id, nil, opts...)
 	if err != nil {
 		return err
 	}
 	return handleResponse(resp, nil)
 }
 
+// ListMetadata returns a single page of metadata entries whose path starts
+// with prefix.
+func (c *Client) ListMetadata(ctx context.Context, prefix string, opts *ListOptions) (*Page[Metadata], error) {
+	resp, err := c.doRequest(ctx, "GET", listPath("/v1/metadata", opts, url.Values{"prefix": {prefix}}), nil)
+	if err != nil {
+		return nil, err
+	}
+	var page Page[Metadata]
+	if err := handleResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllMetadata iterates over every metadata entry whose path starts with
+// prefix, fetching pages as needed.
+func (c *Client) AllMetadata(ctx context.Context, prefix string) iter.Seq2[*Metadata, error] {
+	return listAll(ctx, func(ctx context.Context, opts *ListOptions) (*Page[Metadata], error) {
+		return c.ListMetadata(ctx, prefix, opts)
+	})
+}
+
 // Bucket methods
 
-func (c *Client) CreateBucket(ctx context.Context, name string) (*Bucket, error) {
-	resp, err := c.doRequest(ctx, "POST", "/v1/buckets", map[string]string{"name": name})
+func (c *Client) CreateBucket(ctx context.Context, name string, opts ...RequestOption) (*Bucket, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/buckets", map[string]string{"name": name}, withIdempotencyKey(opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -296,6 +764,7 @@ func (c *Client) CreateBucket(ctx context.Context, name string) (*Bucket, error)
 	if err := handleResponse(resp, &bucket); err != nil {
 		return nil, err
 	}
+	bucket.ETag = resp.Header.Get("ETag")
 	return &bucket, nil
 }
 
@@ -308,11 +777,12 @@ func (c *Client) GetBucket(ctx context.Context, id string) (*Bucket, error) {
 	if err := handleResponse(resp, &bucket); err != nil {
 		return nil, err
 	}
+	bucket.ETag = resp.Header.Get("ETag")
 	return &bucket, nil
 }
 
-func (c *Client) UpdateBucket(ctx context.Context, id, name string) (*Bucket, error) {
-	resp, err := c.doRequest(ctx, "PATCH", "/v1/buckets/"+id, map[string]string{"name": name})
+func (c *Client) UpdateBucket(ctx context.Context, id, name string, opts ...RequestOption) (*Bucket, error) {
+	resp, err := c.doRequest(ctx, "PATCH", "/v1/buckets/"+id, map[string]string{"name": name}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -320,17 +790,36 @@ func (c *Client) UpdateBucket(ctx context.Context, id, name string) (*Bucket, er
 	if err := handleResponse(resp, &bucket); err != nil {
 		return nil, err
 	}
+	bucket.ETag = resp.Header.Get("ETag")
 	return &bucket, nil
 }
 
-func (c *Client) DeleteBucket(ctx context.Context, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/v1/buckets/"+id, nil)
+func (c *Client) DeleteBucket(ctx context.Context, id string, opts ...RequestOption) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/v1/buckets/"+id, nil, opts...)
 	if err != nil {
 		return err
 	}
 	return handleResponse(resp, nil)
 }
 
+// ListBuckets returns a single page of buckets.
+func (c *Client) ListBuckets(ctx context.Context, opts *ListOptions) (*Page[Bucket], error) {
+	resp, err := c.doRequest(ctx, "GET", listPath("/v1/buckets", opts, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	var page Page[Bucket]
+	if err := handleResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllBuckets iterates over every bucket, fetching pages as needed.
+func (c *Client) AllBuckets(ctx context.Context) iter.Seq2[*Bucket, error] {
+	return listAll(ctx, c.ListBuckets)
+}
+
 // Object methods
 
 type CreateObjectRequest struct {
@@ -343,8 +832,8 @@ type UpdateObjectRequest struct {
 	Content *string `json:"content,omitempty"`
 }
 
-func (c *Client) CreateObject(ctx context.Context, bucketID string, req *CreateObjectRequest) (*Object, error) {
-	resp, err := c.doRequest(ctx, "POST", "/v1/bucket/"+bucketID+"/objects", req)
+func (c *Client) CreateObject(ctx context.Context, bucketID string, req *CreateObjectRequest, opts ...RequestOption) (*Object, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/bucket/"+bucketID+"/objects", req, withIdempotencyKey(opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +841,7 @@ func (c *Client) CreateObject(ctx context.Context, bucketID string, req *CreateO
 	if err := handleResponse(resp, &object); err != nil {
 		return nil, err
 	}
+	object.ETag = resp.Header.Get("ETag")
 	return &object, nil
 }
 
@@ -364,11 +854,12 @@ func (c *Client) GetObject(ctx context.Context, bucketID, id string) (*Object, e
 	if err := handleResponse(resp, &object); err != nil {
 		return nil, err
 	}
+	object.ETag = resp.Header.Get("ETag")
 	return &object, nil
 }
 
-func (c *Client) UpdateObject(ctx context.Context, bucketID, id string, req *UpdateObjectRequest) (*Object, error) {
-	resp, err := c.doRequest(ctx, "PATCH", "/v1/bucket/"+bucketID+"/objects/"+id, req)
+func (c *Client) UpdateObject(ctx context.Context, bucketID, id string, req *UpdateObjectRequest, opts ...RequestOption) (*Object, error) {
+	resp, err := c.doRequest(ctx, "PATCH", "/v1/bucket/"+bucketID+"/objects/"+id, req, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -376,11 +867,91 @@ func (c *Client) UpdateObject(ctx context.Context, bucketID, id string, req *Upd
 	if err := handleResponse(resp, &object); err != nil {
 		return nil, err
 	}
+	object.ETag = resp.Header.Get("ETag")
 	return &object, nil
 }
 
-func (c *Client) DeleteObject(ctx context.Context, bucketID, id string) error {
-	resp, err := c.doRequest(ctx, "DELETE", "/v1/bucket/"+bucketID+"/objects/"+id, nil)
+func (c *Client) DeleteObject(ctx context.Context, bucketID, id string, opts ...RequestOption) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/v1/bucket/"+bucketID+"/objects/"+id, nil, opts...)
+	if err != nil {
+		return err
+	}
+	return handleResponse(resp, nil)
+}
+
+// ListObjects returns a single page of the objects in a bucket whose path
+// starts with prefix.
+func (c *Client) ListObjects(ctx context.Context, bucketID, prefix string, opts *ListOptions) (*Page[Object], error) {
+	resp, err := c.doRequest(ctx, "GET", listPath("/v1/bucket/"+bucketID+"/objects", opts, url.Values{"prefix": {prefix}}), nil)
+	if err != nil {
+		return nil, err
+	}
+	var page Page[Object]
+	if err := handleResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllObjects iterates over every object in a bucket whose path starts with
+// prefix, fetching pages as needed.
+func (c *Client) AllObjects(ctx context.Context, bucketID, prefix string) iter.Seq2[*Object, error] {
+	return listAll(ctx, func(ctx context.Context, opts *ListOptions) (*Page[Object], error) {
+		return c.ListObjects(ctx, bucketID, prefix, opts)
+	})
+}
+
+// Token methods
+
+// Token represents a scoped, expiring NahCloud API token. Secret is only set
+// on tokens returned by CreateToken.
+type Token struct {
+	ID        string    `json:"id"`
+	Secret    string    `json:"token"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateTokenRequest struct {
+	// Scopes restricts the token to a subset of the scopes of the client's
+	// credential. All scopes are granted if empty.
+	Scopes []string `json:"scopes,omitempty"`
+	// TTLSeconds is the lifetime of the token. Zero uses the server default.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+// CreateToken exchanges the client's credential for a new token.
+func (c *Client) CreateToken(ctx context.Context, req *CreateTokenRequest, opts ...RequestOption) (*Token, error) {
+	resp, err := c.doRequest(ctx, "POST", "/v1/tokens", req, withIdempotencyKey(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	var token Token
+	if err := handleResponse(resp, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RenewToken extends the lifetime of a token to ttlSeconds from now. Zero
+// uses the server default. The returned token has no Secret.
+func (c *Client) RenewToken(ctx context.Context, id string, ttlSeconds int) (*Token, error) {
+	body := map[string]int{"ttl_seconds": ttlSeconds}
+	resp, err := c.doRequest(ctx, "POST", "/v1/tokens/"+id+"/renew", body, withIdempotencyKey(nil)...)
+	if err != nil {
+		return nil, err
+	}
+	var token Token
+	if err := handleResponse(resp, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeToken invalidates a token before it expires.
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	resp, err := c.doRequest(ctx, "DELETE", "/v1/tokens/"+id, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,367 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypertf/terraform-provider-nah/internal/nahcloud"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+// testRetryPolicy retries quickly so that tests exercising retries stay fast.
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func newTestClient(t *testing.T) (*Client, *nahtest.Server) {
+	t.Helper()
+
+	srv := nahtest.NewServer(t)
+	return NewClient(srv.URL, "test-token", WithRetryPolicy(testRetryPolicy)), srv
+}
+
+func TestClientCRUD(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := t.Context()
+
+	project, err := c.CreateProject(ctx, "crud")
+	if err != nil {
+		t.Fatalf("creating project: %s", err)
+	}
+	instance, err := c.CreateInstance(ctx, &CreateInstanceRequest{
+		ProjectID: project.ID,
+		Name:      "web",
+		CPU:       2,
+		MemoryMB:  1024,
+		Image:     "ubuntu:22.04",
+	})
+	if err != nil {
+		t.Fatalf("creating instance: %s", err)
+	}
+	if instance.Status != "running" {
+		t.Errorf("expected default status running, got %q", instance.Status)
+	}
+	if instance.ETag == "" {
+		t.Error("expected an ETag")
+	}
+
+	cpu := 4
+	updated, err := c.UpdateInstance(ctx, instance.ID, &UpdateInstanceRequest{CPU: &cpu}, IfMatch(instance.ETag))
+	if err != nil {
+		t.Fatalf("updating instance: %s", err)
+	}
+	if updated.CPU != 4 || updated.Name != "web" {
+		t.Errorf("unexpected instance after update: %+v", updated)
+	}
+	if updated.ETag == instance.ETag {
+		t.Error("expected the ETag to change on update")
+	}
+
+	if err := c.DeleteProject(ctx, project.ID); !IsConflict(err) {
+		t.Errorf("expected a conflict deleting a project with instances, got %v", err)
+	}
+	if err := c.DeleteInstance(ctx, instance.ID); err != nil {
+		t.Fatalf("deleting instance: %s", err)
+	}
+	if _, err := c.GetInstance(ctx, instance.ID); !IsNotFound(err) {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestClientInProcessEndpoints(t *testing.T) {
+	statePath := filepath.ToSlash(filepath.Join(t.TempDir(), "state.json"))
+	if !strings.HasPrefix(statePath, "/") {
+		// C:/path
+		statePath = "/" + statePath
+	}
+
+	tests := []struct {
+		endpoint string
+		// shared is another endpoint that serves the same data.
+		shared string
+	}{
+		{"memory://", "memory:///"},
+		{"memory://client-test", "memory://client-test/"},
+		{"file://" + statePath, "file://" + statePath},
+	}
+	for _, tt := range tests {
+		c := NewClient(tt.endpoint, "", WithRetryPolicy(testRetryPolicy))
+		project, err := c.CreateProject(t.Context(), "in-process")
+		if err != nil {
+			t.Errorf("%s: creating project: %s", tt.endpoint, err)
+			continue
+		}
+		if _, err := NewClient(tt.shared, "").GetProject(t.Context(), project.ID); err != nil {
+			t.Errorf("%s: reading project through %s: %s", tt.endpoint, tt.shared, err)
+		}
+		if _, err := NewClient("memory://client-test-other", "").GetProject(t.Context(), project.ID); !IsNotFound(err) {
+			t.Errorf("%s: expected another in-memory backend not to have the project, got %v", tt.endpoint, err)
+		}
+		if err := c.DeleteProject(t.Context(), project.ID); err != nil {
+			t.Errorf("%s: deleting project: %s", tt.endpoint, err)
+		}
+	}
+}
+
+func TestTrimEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, want string
+	}{
+		{"https://nahcloud.com", "https://nahcloud.com"},
+		{"https://nahcloud.com/", "https://nahcloud.com"},
+		{"http://localhost:8080/api/", "http://localhost:8080/api"},
+		{"memory://", "memory://"},
+		{"memory:///", "memory://"},
+		{"memory://name", "memory://name"},
+		{"memory://name/", "memory://name"},
+		{"file:///tmp/state.json", "file:///tmp/state.json"},
+	}
+	for _, tt := range tests {
+		if got := trimEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("trimEndpoint(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestClientTokens(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := t.Context()
+
+	token, err := c.CreateToken(ctx, &CreateTokenRequest{Scopes: []string{"read"}, TTLSeconds: 300})
+	if err != nil {
+		t.Fatalf("creating token: %s", err)
+	}
+	if token.Secret == "" || len(token.Scopes) != 1 || token.Scopes[0] != "read" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+	if ttl := time.Until(token.ExpiresAt); ttl <= 0 || ttl > 5*time.Minute {
+		t.Errorf("expected the token to expire in 5 minutes, got %s", ttl)
+	}
+
+	renewed, err := c.RenewToken(ctx, token.ID, 3600)
+	if err != nil {
+		t.Fatalf("renewing token: %s", err)
+	}
+	if !renewed.ExpiresAt.After(token.ExpiresAt) || renewed.Secret != "" {
+		t.Errorf("unexpected renewed token: %+v", renewed)
+	}
+
+	if _, err := c.CreateToken(ctx, &CreateTokenRequest{Scopes: []string{"admin"}}); !IsBadRequest(err) {
+		t.Errorf("expected an unknown scope to be rejected, got %v", err)
+	}
+
+	if err := c.RevokeToken(ctx, token.ID); err != nil {
+		t.Fatalf("revoking token: %s", err)
+	}
+	if _, err := c.RenewToken(ctx, token.ID, 0); !IsNotFound(err) {
+		t.Errorf("expected not found renewing a revoked token, got %v", err)
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	_, err := c.GetBucket(t.Context(), "bkt-missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "not_found" || apiErr.RequestID == "" {
+		t.Errorf("unexpected API error: %+v", apiErr)
+	}
+}
+
+func TestClientRetriesInjectedErrors(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	srv.InjectStatus("GET /v1/projects", http.StatusServiceUnavailable, 2)
+	if _, err := c.ListProjects(t.Context(), nil); err != nil {
+		t.Fatalf("expected success after retries, got %s", err)
+	}
+	if got := len(srv.Requests()); got != 3 {
+		t.Errorf("expected 3 requests, got %d", got)
+	}
+
+	srv.InjectStatus("GET /v1/projects", http.StatusServiceUnavailable, 0)
+	_, err := c.ListProjects(t.Context(), nil)
+	if !IsServerError(err) {
+		t.Errorf("expected a server error once retries are exhausted, got %v", err)
+	}
+}
+
+func TestClientIdempotentCreateAfterLostResponse(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	srv.DropResponses("POST /v1/projects", 1)
+	project, err := c.CreateProject(t.Context(), "lost")
+	if err != nil {
+		t.Fatalf("creating project: %s", err)
+	}
+
+	var count int
+	srv.Update(func(state *nahcloud.State) { count = len(state.Projects) })
+	if count != 1 {
+		t.Errorf("expected exactly 1 project after a retried create, got %d", count)
+	}
+	if _, err := c.GetProject(t.Context(), project.ID); err != nil {
+		t.Errorf("reading the created project: %s", err)
+	}
+}
+
+func TestClientIfMatchAfterOutOfBandChange(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	metadata, err := c.CreateMetadata(t.Context(), "/app/config", "v1")
+	if err != nil {
+		t.Fatalf("creating metadata: %s", err)
+	}
+	srv.UpdateMetadata(metadata.ID, func(m *nahcloud.Metadata) { m.Value = "changed" })
+
+	value := "v2"
+	_, err = c.UpdateMetadata(t.Context(), metadata.ID, &UpdateMetadataRequest{Value: &value}, IfMatch(metadata.ETag))
+	if !IsPreconditionFailed(err) {
+		t.Errorf("expected precondition failed, got %v", err)
+	}
+
+	srv.Delete(metadata.ID)
+	if _, err := c.GetMetadata(t.Context(), metadata.ID); !IsNotFound(err) {
+		t.Errorf("expected not found after out-of-band delete, got %v", err)
+	}
+}
+
+func TestClientIfMatchAfterLostResponse(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	metadata, err := c.CreateMetadata(t.Context(), "/app/config", "v1")
+	if err != nil {
+		t.Fatalf("creating metadata: %s", err)
+	}
+
+	// The first attempt is applied, so the retry fails its precondition.
+	srv.DropResponses("PATCH /v1/metadata/{id}", 1)
+	value := "v2"
+	updated, err := c.UpdateMetadata(t.Context(), metadata.ID, &UpdateMetadataRequest{Value: &value}, IfMatch(metadata.ETag))
+	if err != nil {
+		t.Fatalf("expected the applied update to succeed, got %s", err)
+	}
+	current, err := c.GetMetadata(t.Context(), metadata.ID)
+	if err != nil {
+		t.Fatalf("reading metadata: %s", err)
+	}
+	if updated.Value != "v2" || updated.ETag != current.ETag {
+		t.Errorf("expected the current metadata %+v, got %+v", current, updated)
+	}
+}
+
+func TestClientIfMatchAfterLostResponseMaxConcurrent(t *testing.T) {
+	srv := nahtest.NewServer(t)
+	c := NewClient(srv.URL, "test-token", WithRetryPolicy(testRetryPolicy), WithMaxConcurrentRequests(1))
+
+	metadata, err := c.CreateMetadata(t.Context(), "/app/config", "v1")
+	if err != nil {
+		t.Fatalf("creating metadata: %s", err)
+	}
+
+	// Reading the object back must not wait for the slot held by the 412.
+	srv.DropResponses("PATCH /v1/metadata/{id}", 1)
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	value := "v2"
+	updated, err := c.UpdateMetadata(ctx, metadata.ID, &UpdateMetadataRequest{Value: &value}, IfMatch(metadata.ETag))
+	if err != nil {
+		t.Fatalf("expected the applied update to succeed, got %s", err)
+	}
+	if updated.Value != "v2" {
+		t.Errorf("expected the updated metadata, got %+v", updated)
+	}
+}
+
+func TestClientIfMatchAfterRetryAndOutOfBandChange(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	metadata, err := c.CreateMetadata(t.Context(), "/app/config", "v1")
+	if err != nil {
+		t.Fatalf("creating metadata: %s", err)
+	}
+	srv.UpdateMetadata(metadata.ID, func(m *nahcloud.Metadata) { m.Value = "changed" })
+
+	// The first attempt fails in a way that may have been applied, but the
+	// object does not have the values sent: the 412 stands.
+	srv.InjectStatus("PATCH /v1/metadata/{id}", http.StatusBadGateway, 1)
+	value := "v2"
+	_, err = c.UpdateMetadata(t.Context(), metadata.ID, &UpdateMetadataRequest{Value: &value}, IfMatch(metadata.ETag))
+	if !IsPreconditionFailed(err) {
+		t.Errorf("expected precondition failed, got %v", err)
+	}
+}
+
+func TestClientContextDeadline(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	srv.InjectLatency("/", time.Second)
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.ListBuckets(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClientResponseHeaderTimeout(t *testing.T) {
+	srv := nahtest.NewServer(t)
+	c := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithResponseHeaderTimeout(20*time.Millisecond))
+
+	// The context has no deadline: the server not answering still ends
+	// every attempt.
+	srv.InjectLatency("/", time.Minute)
+	start := time.Now()
+	if _, err := c.ListBuckets(context.Background(), nil); err == nil {
+		t.Fatal("expected an error from a server that does not answer")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the request to time out, took %s", elapsed)
+	}
+	if got := len(srv.Requests()); got != testRetryPolicy.MaxAttempts {
+		t.Errorf("expected timed out attempts to be retried, got %d requests", got)
+	}
+}
+
+func TestClientResponseHeaderTimeoutWithDeadline(t *testing.T) {
+	srv := nahtest.NewServer(t)
+	c := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithResponseHeaderTimeout(20*time.Millisecond))
+
+	// The context has a deadline, e.g. from the timeouts of a resource: the
+	// server may take longer than the response header timeout to answer.
+	srv.InjectLatency("/", 200*time.Millisecond)
+	ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+	defer cancel()
+	if _, err := c.ListBuckets(ctx, nil); err != nil {
+		t.Fatalf("expected the request to wait for the server, got %s", err)
+	}
+	if got := len(srv.Requests()); got != 1 {
+		t.Errorf("expected a single request, got %d", got)
+	}
+}
+
+func TestClientSendsHeaders(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	bucket, err := c.CreateBucket(t.Context(), "headers")
+	if err != nil {
+		t.Fatalf("creating bucket: %s", err)
+	}
+	if _, err := c.CreateObject(t.Context(), bucket.ID, &CreateObjectRequest{Path: "a.txt", Content: "aGk="}); err != nil {
+		t.Fatalf("creating object: %s", err)
+	}
+	for _, req := range srv.Requests() {
+		if got := req.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("%s %s: unexpected Authorization header %q", req.Method, req.Path, got)
+		}
+		if req.Method == http.MethodPost && req.Header.Get("Idempotency-Key") == "" {
+			t.Errorf("%s %s: missing Idempotency-Key", req.Method, req.Path)
+		}
+	}
+}
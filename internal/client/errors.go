@@ -0,0 +1,140 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the NahCloud API responds with a non-2xx status.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the machine-readable error code, e.g. "not_found".
+	Code string
+	// Message is the human-readable error message.
+	Message string
+	// RequestID identifies the request in the NahCloud server logs.
+	RequestID string
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API error (status %d", e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, ", code %s", e.Code)
+	}
+	b.WriteString(")")
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+// errorDetail is the error object in a NahCloud error response.
+type errorDetail struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// errorResponse is the body of a NahCloud error response. The server nests
+// the details under "error", but flat bodies and plain string errors are
+// accepted as well.
+type errorResponse struct {
+	Error json.RawMessage `json:"error"`
+	errorDetail
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Body:       body,
+	}
+
+	var detail errorDetail
+	var envelope errorResponse
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		detail = envelope.errorDetail
+		if len(envelope.Error) > 0 {
+			var nested errorDetail
+			var message string
+			if err := json.Unmarshal(envelope.Error, &nested); err == nil {
+				detail = nested
+			} else if err := json.Unmarshal(envelope.Error, &message); err == nil {
+				detail.Message = message
+			}
+		}
+	}
+
+	apiErr.Code = detail.Code
+	apiErr.Message = detail.Message
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = detail.RequestID
+	}
+	if apiErr.Message == "" && detail.Code == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// IsNotFound reports whether err is an API error with status 404.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an API error with status 409.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is an API error with status 401.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsPreconditionFailed reports whether err is an API error with status 412,
+// returned when a conditional request's ETag no longer matches.
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, http.StatusPreconditionFailed)
+}
+
+// IsForbidden reports whether err is an API error with status 403.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsBadRequest reports whether err is an API error with status 400 or 422,
+// which NahCloud uses for validation failures.
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest) || hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+// IsRateLimited reports whether err is an API error with status 429.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsServerError reports whether err is an API error with a 5xx status.
+func IsServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		requestID string
+		body      string
+		want      APIError
+	}{
+		{
+			name:   "nested",
+			status: http.StatusNotFound,
+			body:   `{"error":{"code":"not_found","message":"bucket \"bkt-1\" not found","request_id":"req-1"}}`,
+			want:   APIError{Code: "not_found", Message: `bucket "bkt-1" not found`, RequestID: "req-1"},
+		},
+		{
+			name:   "flat",
+			status: http.StatusConflict,
+			body:   `{"code":"conflict","message":"bucket name taken","request_id":"req-1"}`,
+			want:   APIError{Code: "conflict", Message: "bucket name taken", RequestID: "req-1"},
+		},
+		{
+			name:   "string error",
+			status: http.StatusBadRequest,
+			body:   `{"error":"cpu must be positive"}`,
+			want:   APIError{Message: "cpu must be positive"},
+		},
+		{
+			name:   "code without message",
+			status: http.StatusForbidden,
+			body:   `{"error":{"code":"forbidden"}}`,
+			want:   APIError{Code: "forbidden", Message: "Forbidden"},
+		},
+		{
+			name:   "not JSON",
+			status: http.StatusBadGateway,
+			body:   "upstream connect error\n",
+			want:   APIError{Message: "upstream connect error"},
+		},
+		{
+			name:   "JSON without error details",
+			status: http.StatusBadRequest,
+			body:   `{"status":"bad"}`,
+			want:   APIError{Message: `{"status":"bad"}`},
+		},
+		{
+			name:   "empty",
+			status: http.StatusServiceUnavailable,
+			body:   "",
+			want:   APIError{Message: "Service Unavailable"},
+		},
+		{
+			name:      "request ID header",
+			status:    http.StatusNotFound,
+			requestID: "req-header",
+			body:      `{"error":{"code":"not_found","message":"not found","request_id":"req-body"}}`,
+			want:      APIError{Code: "not_found", Message: "not found", RequestID: "req-header"},
+		},
+		{
+			name:      "request ID header only",
+			status:    http.StatusInternalServerError,
+			requestID: "req-header",
+			body:      "",
+			want:      APIError{Message: "Internal Server Error", RequestID: "req-header"},
+		},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		if tt.requestID != "" {
+			resp.Header.Set("X-Request-Id", tt.requestID)
+		}
+
+		got := newAPIError(resp, []byte(tt.body))
+		if got.StatusCode != tt.status || got.Code != tt.want.Code || got.Message != tt.want.Message || got.RequestID != tt.want.RequestID {
+			t.Errorf("%s: newAPIError(%s) = %+v, want %+v", tt.name, tt.body, *got, tt.want)
+		}
+		if string(got.Body) != tt.body {
+			t.Errorf("%s: expected the raw body to be kept, got %q", tt.name, got.Body)
+		}
+	}
+}
+
+func TestAPIErrorStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		is     func(error) bool
+	}{
+		{http.StatusNotFound, IsNotFound},
+		{http.StatusConflict, IsConflict},
+		{http.StatusUnauthorized, IsUnauthorized},
+		{http.StatusForbidden, IsForbidden},
+		{http.StatusPreconditionFailed, IsPreconditionFailed},
+		{http.StatusBadRequest, IsBadRequest},
+		{http.StatusUnprocessableEntity, IsBadRequest},
+		{http.StatusTooManyRequests, IsRateLimited},
+		{http.StatusBadGateway, IsServerError},
+	}
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.status}
+		wrapped := fmt.Errorf("updating bucket: %w", fmt.Errorf("attempt 3: %w", err))
+		if !tt.is(err) || !tt.is(wrapped) {
+			t.Errorf("%d: expected a match for the error and the wrapped error", tt.status)
+		}
+
+		other := fmt.Errorf("updating bucket: %w", &APIError{StatusCode: http.StatusTeapot})
+		if tt.is(other) || tt.is(fmt.Errorf("updating bucket: %s", err)) || tt.is(nil) {
+			t.Errorf("%d: expected no match for another status, an unwrapped message or nil", tt.status)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// FaultInjection configures faults injected into API requests to simulate an
+// unreliable NahCloud server. Faults are injected below the retry logic, so
+// they exercise it like real failures would.
+//
+// For each request in scope a single random draw decides whether it fails
+// with an error status, times out, or has its response lost, with the given
+// probabilities. Rates of 0 and 1 make the outcome deterministic.
+type FaultInjection struct {
+	// Seed seeds the random source, so that runs with the same seed and the
+	// same sequence of requests inject the same faults.
+	Seed uint64
+
+	// Operations restricts injection to the named client operations, such as
+	// "CreateInstance" or "ListBuckets". All operations are in scope if empty.
+	Operations []string
+
+	// Latency is added to every request in scope.
+	Latency time.Duration
+
+	// ErrorRate is the probability that a request fails with one of
+	// ErrorStatusCodes, chosen at random, without reaching the server.
+	ErrorRate        float64
+	ErrorStatusCodes []int
+
+	// TimeoutRate is the probability that a request hangs for Timeout, or
+	// until its context is done, and then fails with a timeout error.
+	TimeoutRate float64
+	Timeout     time.Duration
+
+	// ResponseLostRate is the probability that a request is processed by the
+	// server but its response is discarded and a connection error returned.
+	ResponseLostRate float64
+
+	// MaxFaults limits the number of faults injected over the lifetime of the
+	// client. Zero means no limit.
+	MaxFaults int
+}
+
+// DefaultFaultStatusCodes are the statuses injected when ErrorStatusCodes is
+// empty.
+var DefaultFaultStatusCodes = []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable}
+
+// DefaultFaultTimeout is how long an injected timeout hangs when Timeout is
+// zero.
+const DefaultFaultTimeout = 30 * time.Second
+
+// Validate checks that the rates are probabilities that add up to at most 1
+// and that the status codes are HTTP error statuses.
+func (f *FaultInjection) Validate() error {
+	for name, rate := range map[string]float64{
+		"error rate":         f.ErrorRate,
+		"timeout rate":       f.TimeoutRate,
+		"response lost rate": f.ResponseLostRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1, got %v", name, rate)
+		}
+	}
+	if sum := f.ErrorRate + f.TimeoutRate + f.ResponseLostRate; sum > 1 {
+		return fmt.Errorf("the sum of the error, timeout and response lost rates must not exceed 1, got %v", sum)
+	}
+	for _, code := range f.ErrorStatusCodes {
+		if code < 400 || code > 599 {
+			return fmt.Errorf("error status code %d is not an HTTP error status", code)
+		}
+	}
+	return nil
+}
+
+// WithFaultInjection injects faults into requests as configured by f.
+func WithFaultInjection(f *FaultInjection) Option {
+	return func(c *Client) {
+		c.faultInjection = f
+	}
+}
+
+// errResponseLost is returned when an injected fault discards a response.
+var errResponseLost = errors.New("connection reset after the request was sent (response lost, injected fault)")
+
+// faultTimeoutError is returned for injected timeouts. It implements
+// net.Error so that it is treated like a real network timeout.
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "request timed out (injected fault)" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
+
+// faultTransport injects faults into requests sent through base.
+type faultTransport struct {
+	base   http.RoundTripper
+	config FaultInjection
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	faults int
+}
+
+func newFaultTransport(base http.RoundTripper, f *FaultInjection) *faultTransport {
+	t := &faultTransport{
+		base:   base,
+		config: *f,
+		rand:   rand.New(rand.NewPCG(f.Seed, f.Seed)),
+	}
+	if len(t.config.ErrorStatusCodes) == 0 {
+		t.config.ErrorStatusCodes = DefaultFaultStatusCodes
+	}
+	if t.config.Timeout == 0 {
+		t.config.Timeout = DefaultFaultTimeout
+	}
+	return t
+}
+
+type fault int
+
+const (
+	faultNone fault = iota
+	faultError
+	faultTimeout
+	faultResponseLost
+)
+
+// draw decides which fault, if any, to inject into the next request. For
+// error faults it also returns the status code to respond with.
+func (t *faultTransport) draw() (fault, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.config.MaxFaults > 0 && t.faults >= t.config.MaxFaults {
+		return faultNone, 0
+	}
+
+	// Always consume the same number of random values, so that the faults
+	// injected into a request do not depend on earlier outcomes.
+	r := t.rand.Float64()
+	code := t.config.ErrorStatusCodes[t.rand.IntN(len(t.config.ErrorStatusCodes))]
+
+	f := faultNone
+	switch {
+	case r < t.config.ErrorRate:
+		f = faultError
+	case r < t.config.ErrorRate+t.config.TimeoutRate:
+		f = faultTimeout
+	case r < t.config.ErrorRate+t.config.TimeoutRate+t.config.ResponseLostRate:
+		f = faultResponseLost
+	}
+	if f != faultNone {
+		t.faults++
+	}
+	return f, code
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := operationName(req.Method, req.URL.Path)
+	if len(t.config.Operations) > 0 && !slices.Contains(t.config.Operations, op) {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	if t.config.Latency > 0 {
+		if err := sleep(ctx, t.config.Latency); err != nil {
+			return nil, err
+		}
+	}
+
+	f, code := t.draw()
+	fields := map[string]interface{}{"operation": op}
+	switch f {
+	case faultError:
+		fields["status"] = code
+		tflog.Warn(ctx, "Injecting error response", fields)
+		return faultResponse(req, code), nil
+
+	case faultTimeout:
+		tflog.Warn(ctx, "Injecting request timeout", fields)
+		if err := sleep(ctx, t.config.Timeout); err != nil {
+			return nil, err
+		}
+		return nil, faultTimeoutError{}
+
+	case faultResponseLost:
+		tflog.Warn(ctx, "Injecting lost response", fields)
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, errResponseLost
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// faultResponse returns an error response with the given status, shaped like
+// those sent by NahCloud.
+func faultResponse(req *http.Request, code int) *http.Response {
+	body, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]string{
+			"code":    "injected_fault",
+			"message": http.StatusText(code) + " (injected fault)",
+		},
+	})
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// operationName returns the name of the client method that issues a request,
+// e.g. "CreateInstance" for POST /v1/instances.
+func operationName(method, path string) string {
+	// Endpoints may have a path of their own, e.g. file:// endpoints.
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if i := slices.Index(segments, "v1"); i >= 0 {
+		segments = segments[i+1:]
+	}
+
+	var kind string
+	var hasID bool
+	switch {
+	case len(segments) == 3 && segments[0] == "tokens" && segments[2] == "renew":
+		return "RenewToken"
+	case len(segments) == 2 && segments[0] == "tokens" && method == http.MethodDelete:
+		return "RevokeToken"
+	case len(segments) >= 3 && segments[0] == "bucket" && segments[2] == "objects":
+		kind, hasID = "Object", len(segments) > 3
+	case len(segments) >= 1:
+		kind, hasID = map[string]string{
+			"projects":  "Project",
+			"instances": "Instance",
+			"metadata":  "Metadata",
+			"buckets":   "Bucket",
+			"tokens":    "Token",
+		}[segments[0]], len(segments) > 1
+	}
+	if kind == "" {
+		return method + " " + path
+	}
+
+	switch {
+	case method == http.MethodPost:
+		return "Create" + kind
+	case method == http.MethodGet && !hasID:
+		if kind == "Metadata" {
+			return "ListMetadata"
+		}
+		return "List" + kind + "s"
+	case method == http.MethodGet:
+		return "Get" + kind
+	case method == http.MethodPatch:
+		return "Update" + kind
+	case method == http.MethodDelete:
+		return "Delete" + kind
+	}
+	return method + " " + path
+}
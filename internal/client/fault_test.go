@@ -0,0 +1,143 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hypertf/terraform-provider-nah/internal/nahcloud"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{http.MethodPost, "/v1/instances", "CreateInstance"},
+		{http.MethodGet, "/v1/instances", "ListInstances"},
+		{http.MethodGet, "/v1/instances/inst-1", "GetInstance"},
+		{http.MethodPatch, "/v1/projects/proj-1", "UpdateProject"},
+		{http.MethodDelete, "/v1/buckets/bkt-1", "DeleteBucket"},
+		{http.MethodGet, "/v1/metadata", "ListMetadata"},
+		{http.MethodGet, "/v1/bucket/bkt-1/objects", "ListObjects"},
+		{http.MethodPost, "/v1/bucket/bkt-1/objects", "CreateObject"},
+		{http.MethodGet, "/tmp/state.json/v1/bucket/bkt-1/objects/obj-1", "GetObject"},
+		{http.MethodPost, "/v1/tokens", "CreateToken"},
+		{http.MethodPost, "/v1/tokens/tok-1/renew", "RenewToken"},
+		{http.MethodDelete, "/v1/tokens/tok-1", "RevokeToken"},
+		{http.MethodGet, "/healthz", "GET /healthz"},
+	}
+	for _, tt := range tests {
+		if got := operationName(tt.method, tt.path); got != tt.want {
+			t.Errorf("operationName(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func newFaultTestClient(t *testing.T, f *FaultInjection, opts ...Option) (*Client, *nahtest.Server) {
+	t.Helper()
+
+	if err := f.Validate(); err != nil {
+		t.Fatalf("invalid fault injection: %s", err)
+	}
+	srv := nahtest.NewServer(t)
+	opts = append([]Option{WithRetryPolicy(testRetryPolicy), WithFaultInjection(f)}, opts...)
+	return NewClient(srv.URL, "", opts...), srv
+}
+
+func TestFaultInjectionDeterministic(t *testing.T) {
+	c, srv := newFaultTestClient(t, &FaultInjection{
+		Operations:       []string{"CreateProject"},
+		ErrorRate:        1,
+		ErrorStatusCodes: []int{http.StatusServiceUnavailable},
+		MaxFaults:        2,
+	})
+
+	if _, err := c.CreateProject(t.Context(), "flaky"); err != nil {
+		t.Fatalf("expected the create to succeed on the third attempt, got %s", err)
+	}
+	if got := len(srv.Requests()); got != 1 {
+		t.Errorf("expected injected errors not to reach the server, got %d requests", got)
+	}
+
+	// Other operations are out of scope.
+	if _, err := c.ListProjects(t.Context(), nil); err != nil {
+		t.Errorf("expected ListProjects to be unaffected, got %s", err)
+	}
+}
+
+func TestFaultInjectionResponseLost(t *testing.T) {
+	c, srv := newFaultTestClient(t, &FaultInjection{
+		Operations:       []string{"CreateBucket"},
+		ResponseLostRate: 1,
+		MaxFaults:        1,
+	})
+
+	if _, err := c.CreateBucket(t.Context(), "lost-response"); err != nil {
+		t.Fatalf("expected the retried create to succeed, got %s", err)
+	}
+	var buckets int
+	srv.Update(func(state *nahcloud.State) { buckets = len(state.Buckets) })
+	if buckets != 1 {
+		t.Errorf("expected exactly 1 bucket, got %d", buckets)
+	}
+	if got := len(srv.Requests()); got != 2 {
+		t.Errorf("expected the request to reach the server twice, got %d", got)
+	}
+}
+
+func TestFaultInjectionTimeout(t *testing.T) {
+	c, _ := newFaultTestClient(t, &FaultInjection{
+		TimeoutRate: 1,
+		Timeout:     time.Millisecond,
+	})
+
+	_, err := c.ListBuckets(t.Context(), nil)
+	var timeout interface{ Timeout() bool }
+	if !errors.As(err, &timeout) || !timeout.Timeout() {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestFaultInjectionSeeded(t *testing.T) {
+	run := func(seed uint64) []int {
+		c, _ := newFaultTestClient(t, &FaultInjection{
+			Seed:      seed,
+			ErrorRate: 0.5,
+		}, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+		var statuses []int
+		for range 20 {
+			_, err := c.ListProjects(t.Context(), nil)
+			status := http.StatusOK
+			var apiErr *APIError
+			if errors.As(err, &apiErr) {
+				status = apiErr.StatusCode
+			}
+			statuses = append(statuses, status)
+		}
+		return statuses
+	}
+
+	first, second := run(42), run(42)
+	if !slices.Equal(first, second) {
+		t.Errorf("expected the same faults for the same seed, got %v and %v", first, second)
+	}
+	if !slices.Contains(first, http.StatusOK) || slices.Equal(first, slices.Repeat([]int{http.StatusOK}, len(first))) {
+		t.Errorf("expected a mix of successes and failures, got %v", first)
+	}
+}
+
+func TestFaultInjectionValidate(t *testing.T) {
+	for _, f := range []*FaultInjection{
+		{ErrorRate: 1.5},
+		{ErrorRate: 0.6, TimeoutRate: 0.6},
+		{ErrorStatusCodes: []int{200}},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", f)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// LogSubsystem is the terraform-plugin-log subsystem that HTTP traffic is
+// logged under. Its level can be set independently with
+// TF_LOG_PROVIDER_NAH_HTTP.
+const LogSubsystem = "nah_http"
+
+// DefaultLogMaxBodySize is the number of body bytes logged per request or
+// response when no limit is configured.
+const DefaultLogMaxBodySize = 4096
+
+const redacted = "[REDACTED]"
+
+// sensitiveBodyFields are JSON fields whose values are masked in logged
+// request and response bodies.
+var sensitiveBodyFields = map[string]bool{
+	"content": true,
+	"value":   true,
+	"token":   true,
+}
+
+// loggingTransport logs every request and response through tflog. Request
+// lines, status and latency are logged at DEBUG; headers and bodies at TRACE.
+type loggingTransport struct {
+	base             http.RoundTripper
+	maxBodySize      int
+	sensitiveHeaders map[string]bool
+	secrets          []string
+}
+
+func newLoggingTransport(base http.RoundTripper, maxBodySize int) *loggingTransport {
+	return &loggingTransport{
+		base:        base,
+		maxBodySize: maxBodySize,
+		sensitiveHeaders: map[string]bool{
+			"Authorization": true,
+		},
+	}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := tflog.NewSubsystem(req.Context(), LogSubsystem,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_NAH_HTTP"),
+		tflog.WithRootFields(),
+	)
+	if len(t.secrets) > 0 {
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, LogSubsystem, t.secrets...)
+	}
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "http_method", req.Method)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "http_url", req.URL.String())
+
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Sending HTTP request")
+	tflog.SubsystemTrace(ctx, LogSubsystem, "HTTP request details", map[string]interface{}{
+		"http_headers": t.headers(req.Header),
+		"http_body":    t.body(reqBody),
+	})
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "http_duration_ms", time.Since(start).Milliseconds())
+	if err != nil {
+		tflog.SubsystemDebug(ctx, LogSubsystem, "HTTP request failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "http_status", resp.StatusCode)
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Received HTTP response")
+	tflog.SubsystemTrace(ctx, LogSubsystem, "HTTP response details", map[string]interface{}{
+		"http_headers": t.headers(resp.Header),
+		"http_body":    t.body(respBody),
+	})
+
+	return resp, nil
+}
+
+// headers returns h flattened for logging, with sensitive values masked.
+func (t *loggingTransport) headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if t.sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = redacted
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// body returns b for logging, with sensitive JSON fields masked and the
+// result truncated to the configured size. Bodies are omitted when the size
+// is zero.
+func (t *loggingTransport) body(b []byte) string {
+	if len(b) == 0 || t.maxBodySize == 0 {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err == nil {
+		if masked, err := json.Marshal(redactJSON(v)); err == nil {
+			b = masked
+		}
+	}
+
+	if t.maxBodySize >= 0 && len(b) > t.maxBodySize {
+		return string(b[:t.maxBodySize]) + "...(truncated)"
+	}
+	return string(b)
+}
+
+// redactJSON masks the values of sensitive fields anywhere in v.
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if sensitiveBodyFields[k] {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactJSON(fv)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
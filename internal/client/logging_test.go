@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestLoggingTransportBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBodySize int
+		body        string
+		want        string
+	}{
+		{"empty", 4096, "", ""},
+		{"omitted", 0, `{"name":"web"}`, ""},
+		{"unlimited", -1, `{"name":"web"}`, `{"name":"web"}`},
+		{"truncated", 8, `{"name":"web"}`, `{"name":...(truncated)`},
+		{"exact size", 14, `{"name":"web"}`, `{"name":"web"}`},
+		{"not JSON", 4096, "plain text", "plain text"},
+		{"content", 4096, `{"content":"c2VjcmV0","path":"a.txt"}`, `{"content":"[REDACTED]","path":"a.txt"}`},
+		{"value", 4096, `{"path":"/app/db","value":"s3cret"}`, `{"path":"/app/db","value":"[REDACTED]"}`},
+		{"token", 4096, `{"id":"tok-1","token":"nah_s3cret"}`, `{"id":"tok-1","token":"[REDACTED]"}`},
+		{"nested", 4096, `{"items":[{"value":"a"},{"value":"b"}]}`, `{"items":[{"value":"[REDACTED]"},{"value":"[REDACTED]"}]}`},
+		{"null", 4096, `{"value":null}`, `{"value":"[REDACTED]"}`},
+		// Masking happens before truncation, so a secret is never cut in
+		// a way that leaks its prefix.
+		{"masked then truncated", 12, `{"value":"s3cret"}`, `{"value":"[R...(truncated)`},
+	}
+	for _, tt := range tests {
+		transport := newLoggingTransport(nil, tt.maxBodySize)
+		if got := transport.body([]byte(tt.body)); got != tt.want {
+			t.Errorf("%s: body(%s) = %q, want %q", tt.name, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestLoggingTransportHeaders(t *testing.T) {
+	transport := newLoggingTransport(nil, DefaultLogMaxBodySize)
+	transport.sensitiveHeaders["X-Api-Key"] = true
+
+	tests := []struct {
+		header, value, want string
+	}{
+		{"Authorization", "Bearer s3cret", redacted},
+		{"authorization", "Bearer s3cret", redacted},
+		{"X-Api-Key", "s3cret", redacted},
+		{"x-api-key", "s3cret", redacted},
+		{"X-Tenant", "acme", "acme"},
+		{"User-Agent", DefaultUserAgent, DefaultUserAgent},
+	}
+	for _, tt := range tests {
+		// Use the header name as given, as http.Header.Set would
+		// canonicalize it.
+		got := transport.headers(http.Header{tt.header: []string{tt.value}})
+		if got[tt.header] != tt.want {
+			t.Errorf("%s: logged %q, want %q", tt.header, got[tt.header], tt.want)
+		}
+	}
+}
+
+func TestLoggingTransportMasksSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBodySize int
+		absent      []string
+		present     []string
+	}{
+		{
+			name:        "bodies",
+			maxBodySize: DefaultLogMaxBodySize,
+			absent:      []string{"test-token", "tenant-s3cret", "metadata-s3cret"},
+			present:     []string{"X-Tenant", "acme", `\"path\":\"/app/db\"`, redacted},
+		},
+		{
+			name:        "bodies omitted",
+			maxBodySize: 0,
+			absent:      []string{"test-token", "tenant-s3cret", "metadata-s3cret", "/app/db", "truncated"},
+			present:     []string{"X-Tenant", "acme"},
+		},
+	}
+	for _, tt := range tests {
+		srv := nahtest.NewServer(t)
+		var output bytes.Buffer
+		ctx := tflogtest.RootLogger(t.Context(), &output)
+
+		c := NewClient(srv.URL, "test-token",
+			WithLogMaxBodySize(tt.maxBodySize),
+			WithHeader("X-Tenant", "acme"),
+			WithSensitiveHeader("X-Api-Key", "tenant-s3cret"),
+		)
+		if _, err := c.CreateMetadata(ctx, "/app/db", "metadata-s3cret"); err != nil {
+			t.Fatalf("%s: creating metadata: %s", tt.name, err)
+		}
+
+		logs := output.String()
+		if !strings.Contains(logs, "HTTP request details") {
+			t.Fatalf("%s: expected HTTP trace logs, got:\n%s", tt.name, logs)
+		}
+		for _, s := range tt.absent {
+			if strings.Contains(logs, s) {
+				t.Errorf("%s: expected %q not to be logged, got:\n%s", tt.name, s, logs)
+			}
+		}
+		for _, s := range tt.present {
+			if !strings.Contains(logs, s) {
+				t.Errorf("%s: expected %q to be logged, got:\n%s", tt.name, s, logs)
+			}
+		}
+	}
+}
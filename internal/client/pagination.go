@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions controls the pagination of List calls.
+type ListOptions struct {
+	// PageSize is the maximum number of items per page. Zero uses the server
+	// default.
+	PageSize int
+	// Cursor is the NextCursor of the previous page. An empty cursor starts
+	// from the first page.
+	Cursor string
+}
+
+// Page is a single page of results returned by a List call.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor is passed as ListOptions.Cursor to fetch the next page. It
+	// is empty on the last page.
+	NextCursor string `json:"next_cursor"`
+}
+
+// listPath returns path with the pagination options and filters encoded in
+// its query string.
+func listPath(path string, opts *ListOptions, filters url.Values) string {
+	q := url.Values{}
+	for k, v := range filters {
+		if len(v) > 0 && v[0] != "" {
+			q[k] = v
+		}
+	}
+	if opts != nil {
+		if opts.PageSize > 0 {
+			q.Set("page_size", strconv.Itoa(opts.PageSize))
+		}
+		if opts.Cursor != "" {
+			q.Set("cursor", opts.Cursor)
+		}
+	}
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
+
+// listAll returns an iterator over every item of every page returned by
+// list. Iteration stops after the first error, which is yielded with a nil
+// item.
+func listAll[T any](ctx context.Context, list func(context.Context, *ListOptions) (*Page[T], error)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		opts := &ListOptions{}
+		for {
+			page, err := list(ctx, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range page.Items {
+				if !yield(&page.Items[i], nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			if page.NextCursor == opts.Cursor {
+				yield(nil, fmt.Errorf("server returned cursor %q twice", page.NextCursor))
+				return
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestAllProjects(t *testing.T) {
+	pages := map[string]Page[Project]{
+		"":   {Items: []Project{{ID: "p1"}, {ID: "p2"}}, NextCursor: "c1"},
+		"c1": {Items: []Project{{ID: "p3"}}, NextCursor: "c2"},
+		"c2": {Items: []Project{{ID: "p4"}}},
+	}
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.URL.Query().Get("page_size"); got != "" {
+			t.Errorf("unexpected page_size %q", got)
+		}
+		_ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("cursor")])
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient(srv.URL, "")
+
+	var ids []string
+	for project, err := range c.AllProjects(t.Context()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids = append(ids, project.ID)
+	}
+	if want := []string{"p1", "p2", "p3", "p4"}; !slices.Equal(ids, want) {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	requests = 0
+	for project := range c.AllProjects(t.Context()) {
+		if project.ID == "p2" {
+			break
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected breaking out of the loop to stop paging after 1 request, got %d", requests)
+	}
+}
+
+func TestListObjects_query(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/bucket/b1/objects" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("prefix") != "logs/" || q.Get("page_size") != "10" || q.Get("cursor") != "abc" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":"o1","bucket_id":"b1","path":"logs/a"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	page, err := NewClient(srv.URL, "").ListObjects(t.Context(), "b1", "logs/", &ListOptions{PageSize: 10, Cursor: "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Path != "logs/a" || page.NextCursor != "" {
+		t.Errorf("unexpected page %+v", page)
+	}
+}
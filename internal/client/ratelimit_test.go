@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingTransport holds every request until release is closed, recording
+// the highest number of requests it held at once.
+type blockingTransport struct {
+	release  chan struct{}
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (t *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := t.inFlight.Add(1)
+	defer t.inFlight.Add(-1)
+	for {
+		peak := t.peak.Load()
+		if n <= peak || t.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	select {
+	case <-t.release:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func newLimitedTestRequest(t *testing.T, ctx context.Context) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://nah.test/v1/projects", nil)
+	if err != nil {
+		t.Fatalf("creating request: %s", err)
+	}
+	return req
+}
+
+func TestLimitedTransportMaxConcurrent(t *testing.T) {
+	base := &blockingTransport{release: make(chan struct{})}
+	transport := newLimitedTransport(base, 0, 2)
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Go(func() {
+			resp, err := transport.RoundTrip(newLimitedTestRequest(t, t.Context()))
+			if err != nil {
+				t.Errorf("round trip: %s", err)
+				return
+			}
+			resp.Body.Close()
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := base.inFlight.Load(); got != 2 {
+		t.Errorf("expected 2 requests in flight, got %d", got)
+	}
+	close(base.release)
+	wg.Wait()
+
+	if got := base.peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestLimitedTransportReleasesOnBodyClose(t *testing.T) {
+	base := &blockingTransport{release: make(chan struct{})}
+	close(base.release)
+	transport := newLimitedTransport(base, 0, 1)
+
+	resp, err := transport.RoundTrip(newLimitedTestRequest(t, t.Context()))
+	if err != nil {
+		t.Fatalf("round trip: %s", err)
+	}
+
+	// The slot is held until the body is closed.
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := transport.RoundTrip(newLimitedTestRequest(t, ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second request to wait for a slot, got %v", err)
+	}
+
+	resp.Body.Close()
+	resp.Body.Close()
+	for range 2 {
+		resp, err := transport.RoundTrip(newLimitedTestRequest(t, t.Context()))
+		if err != nil {
+			t.Fatalf("expected the slot to be released once, got %s", err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestLimitedTransportRequestsPerSecond(t *testing.T) {
+	base := &blockingTransport{release: make(chan struct{})}
+	close(base.release)
+	transport := newLimitedTransport(base, 20, 0)
+
+	start := time.Now()
+	for range 30 {
+		resp, err := transport.RoundTrip(newLimitedTestRequest(t, t.Context()))
+		if err != nil {
+			t.Fatalf("round trip: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The first 20 requests use up the burst, the next 10 take half a second.
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected requests to be throttled, 30 requests took %s", elapsed)
+	}
+}
+
+func TestLimitedTransportRequestsPerSecondContextCanceled(t *testing.T) {
+	base := &blockingTransport{release: make(chan struct{})}
+	close(base.release)
+	transport := newLimitedTransport(base, 0.1, 1)
+
+	resp, err := transport.RoundTrip(newLimitedTestRequest(t, t.Context()))
+	if err != nil {
+		t.Fatalf("round trip: %s", err)
+	}
+	resp.Body.Close()
+
+	// The next token is 10s away: waiting for it is abandoned as soon as the
+	// context is canceled.
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := transport.RoundTrip(newLimitedTestRequest(t, ctx)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the wait to stop on cancellation, took %s", elapsed)
+	}
+	if got := base.inFlight.Load(); got != 0 {
+		t.Errorf("expected the request not to be sent, got %d in flight", got)
+	}
+
+	// The concurrency slot was released.
+	if len(transport.sem) != 0 {
+		t.Errorf("expected the concurrency slot to be released, got %d held", len(transport.sem))
+	}
+}
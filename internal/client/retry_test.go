@@ -0,0 +1,85 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	retryAfter := func(v string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{v}}}
+	}
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		resp    *http.Response
+		want    time.Duration
+	}{
+		{"first retry", policy, 1, nil, time.Second},
+		{"second retry", policy, 2, nil, 2 * time.Second},
+		{"fourth retry", policy, 4, nil, 8 * time.Second},
+		{"capped", policy, 5, nil, 10 * time.Second},
+		{"capped far beyond", policy, 100, nil, 10 * time.Second},
+		{"uncapped", RetryPolicy{BaseDelay: time.Second}, 5, nil, 16 * time.Second},
+		{"no base delay", RetryPolicy{MaxDelay: time.Second}, 3, nil, 0},
+		{"response without Retry-After", policy, 2, &http.Response{Header: http.Header{}}, 2 * time.Second},
+		{"Retry-After seconds", policy, 1, retryAfter("3"), 3 * time.Second},
+		{"Retry-After zero", policy, 3, retryAfter("0"), 0},
+		// The server knows best, so Retry-After is not capped.
+		{"Retry-After beyond max delay", policy, 1, retryAfter("60"), time.Minute},
+		{"Retry-After negative", policy, 2, retryAfter("-1"), 2 * time.Second},
+		{"Retry-After invalid", policy, 2, retryAfter("soon"), 2 * time.Second},
+		{"Retry-After date in the past", policy, 2, retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"), 0},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.delay(tt.attempt, tt.resp); got != tt.want {
+			t.Errorf("%s: delay(%d) = %s, want %s", tt.name, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyDelayRetryAfterDate(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{date}}}
+
+	// HTTP dates have a resolution of one second.
+	if got := policy.delay(1, resp); got < 28*time.Second || got > 30*time.Second {
+		t.Errorf("expected a delay of about 30s, got %s", got)
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{10, 5 * time.Second, 10 * time.Second},
+	}
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second, Jitter: true}
+	for _, tt := range tests {
+		seen := map[time.Duration]bool{}
+		for range 100 {
+			got := policy.delay(tt.attempt, nil)
+			if got < tt.min || got > tt.max {
+				t.Fatalf("delay(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+			}
+			seen[got] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("delay(%d): expected jitter to vary the delay", tt.attempt)
+		}
+	}
+
+	// Retry-After is honored as is.
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	if got := policy.delay(1, resp); got != 2*time.Second {
+		t.Errorf("expected Retry-After not to be jittered, got %s", got)
+	}
+}
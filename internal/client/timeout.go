@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// errResponseHeaderTimeout is returned when the server does not start
+// responding within the response header timeout.
+var errResponseHeaderTimeout = errors.New("timeout awaiting response headers")
+
+// headerTimeoutTransport abandons requests whose context has no deadline if
+// the server does not start responding within timeout, so that callers
+// without a deadline never wait forever. Requests with a deadline, e.g. from
+// the timeouts of a Terraform operation, wait as long as it allows.
+type headerTimeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func newHeaderTimeoutTransport(base http.RoundTripper, timeout time.Duration) http.RoundTripper {
+	if timeout <= 0 {
+		return base
+	}
+	return &headerTimeoutTransport{base: base, timeout: timeout}
+}
+
+func (t *headerTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Deadline(); ok {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(t.timeout, cancel)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		return nil, errResponseHeaderTimeout
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The body is read under the same context, so it is only canceled once
+	// the body is closed.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// TLSConfig describes how the client authenticates the NahCloud server and,
+// for mutual TLS, itself.
+type TLSConfig struct {
+	// CACertPEM contains PEM-encoded CA certificates trusted in addition to
+	// the system roots.
+	CACertPEM []byte
+	// ClientCertPEM and ClientKeyPEM contain the PEM-encoded certificate and
+	// private key presented to servers that require mutual TLS.
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// Build returns the crypto/tls configuration described by c.
+func (c TLSConfig) Build() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if len(c.CACertPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(c.CACertPEM) {
+			return nil, errors.New("no valid PEM certificates found in CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case len(c.ClientCertPEM) > 0 && len(c.ClientKeyPEM) > 0:
+		cert, err := tls.X509KeyPair(c.ClientCertPEM, c.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case len(c.ClientCertPEM) > 0 || len(c.ClientKeyPEM) > 0:
+		return nil, errors.New("client certificate and client key must be set together")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTLSTestServer(t *testing.T, configure func(*tls.Config)) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"proj-1","name":"tls"}`))
+	}))
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	if configure != nil {
+		configure(srv.TLS)
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func serverCAPEM(srv *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+}
+
+func newTLSTestClient(t *testing.T, srv *httptest.Server, cfg TLSConfig) *Client {
+	t.Helper()
+
+	tlsConfig, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("building TLS config: %s", err)
+	}
+	return NewClient(srv.URL, "", WithTLSConfig(tlsConfig), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+}
+
+// generateClientCert returns a self-signed client certificate and key.
+func generateClientCert(t *testing.T) (certPEM, keyPEM []byte, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "nah-test-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, cert
+}
+
+func TestTLSConfig_privateCA(t *testing.T) {
+	srv := newTLSTestServer(t, nil)
+
+	if _, err := newTLSTestClient(t, srv, TLSConfig{}).GetProject(t.Context(), "proj-1"); err == nil {
+		t.Fatal("expected certificate verification to fail without the server CA")
+	}
+
+	project, err := newTLSTestClient(t, srv, TLSConfig{CACertPEM: serverCAPEM(srv)}).GetProject(t.Context(), "proj-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if project.Name != "tls" {
+		t.Errorf("expected project name %q, got %q", "tls", project.Name)
+	}
+}
+
+func TestTLSConfig_insecureSkipVerify(t *testing.T) {
+	srv := newTLSTestServer(t, nil)
+
+	if _, err := newTLSTestClient(t, srv, TLSConfig{InsecureSkipVerify: true}).GetProject(t.Context(), "proj-1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestTLSConfig_mutualTLS(t *testing.T) {
+	certPEM, keyPEM, cert := generateClientCert(t)
+	srv := newTLSTestServer(t, func(cfg *tls.Config) {
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	})
+
+	if _, err := newTLSTestClient(t, srv, TLSConfig{CACertPEM: serverCAPEM(srv)}).GetProject(t.Context(), "proj-1"); err == nil {
+		t.Fatal("expected the server to reject a client without a certificate")
+	}
+
+	c := newTLSTestClient(t, srv, TLSConfig{
+		CACertPEM:     serverCAPEM(srv),
+		ClientCertPEM: certPEM,
+		ClientKeyPEM:  keyPEM,
+	})
+	if _, err := c.GetProject(t.Context(), "proj-1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestTLSConfig_Build_errors(t *testing.T) {
+	certPEM, _, _ := generateClientCert(t)
+
+	testCases := map[string]TLSConfig{
+		"invalid CA":       {CACertPEM: []byte("not a certificate")},
+		"cert without key": {ClientCertPEM: certPEM},
+		"mismatched key":   {ClientCertPEM: certPEM, ClientKeyPEM: []byte("not a key")},
+		"key without cert": {ClientKeyPEM: []byte("key")},
+	}
+
+	for name, cfg := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := cfg.Build(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
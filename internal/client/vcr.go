@@ -0,0 +1,319 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hypertf/terraform-provider-nah/internal/filelock"
+)
+
+// VCRMode selects whether API interactions are recorded to or replayed from
+// a cassette file.
+type VCRMode string
+
+const (
+	// VCRRecord sends requests to the server and appends every interaction
+	// to the cassette. Interactions are appended while holding a lock on the
+	// cassette, so the separate provider processes of a Terraform run can
+	// share one; delete it to record from scratch.
+	VCRRecord VCRMode = "record"
+
+	// VCRReplay serves requests from the cassette without contacting any
+	// server. Each client replays the cassette from the start.
+	VCRReplay VCRMode = "replay"
+)
+
+// cassetteVersion is the version of the cassette file format.
+const cassetteVersion = 1
+
+// Cassette is a recording of API interactions.
+type Cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and the response it received. URLs are
+// relative to the client endpoint.
+type Interaction struct {
+	Request struct {
+		Method  string      `json:"method"`
+		URL     string      `json:"url"`
+		Headers http.Header `json:"headers,omitempty"`
+		Body    string      `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int         `json:"status_code"`
+		Headers    http.Header `json:"headers,omitempty"`
+		Body       string      `json:"body,omitempty"`
+	} `json:"response"`
+
+	used bool
+}
+
+// WithVCR records API interactions to, or replays them from, the cassette
+// file at path. Credentials are scrubbed from recorded cassettes.
+func WithVCR(mode VCRMode, path string) Option {
+	return func(c *Client) {
+		c.vcrMode = mode
+		c.vcrCassette = path
+	}
+}
+
+// vcrTransport records interactions with base to a cassette, or replays them
+// from one.
+type vcrTransport struct {
+	base     http.RoundTripper
+	mode     VCRMode
+	path     string
+	endpoint string
+
+	// secrets are scrubbed from recorded headers and bodies.
+	secrets          []string
+	sensitiveHeaders map[string]bool
+
+	mu sync.Mutex
+	// cassette is the cassette being replayed, loaded on first use.
+	loaded   bool
+	loadErr  error
+	cassette *Cassette
+}
+
+func newVCRTransport(base http.RoundTripper, mode VCRMode, path, endpoint string) *vcrTransport {
+	return &vcrTransport{
+		base:     base,
+		mode:     mode,
+		path:     path,
+		endpoint: endpoint,
+		sensitiveHeaders: map[string]bool{
+			"Authorization": true,
+		},
+	}
+}
+
+func (t *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	url := strings.TrimPrefix(req.URL.String(), t.endpoint)
+
+	if t.mode == VCRReplay {
+		if err := t.load(); err != nil {
+			return nil, err
+		}
+		return t.replay(req, url, string(reqBody))
+	}
+	return t.record(req, url, reqBody)
+}
+
+// load reads the cassette to replay on first use. t.mu must be held.
+func (t *vcrTransport) load() error {
+	if t.loaded {
+		return t.loadErr
+	}
+	t.loaded = true
+
+	t.cassette, t.loadErr = readCassette(t.path)
+	if errors.Is(t.loadErr, fs.ErrNotExist) {
+		t.loadErr = fmt.Errorf("cassette %s does not exist; record it first with NAH_VCR_MODE=record", t.path)
+	}
+	return t.loadErr
+}
+
+// readCassette reads and decodes the cassette file at path. The error wraps
+// fs.ErrNotExist if there is no such file.
+func readCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	if cassette.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s has unsupported version %d", path, cassette.Version)
+	}
+	return cassette, nil
+}
+
+// replay returns the response of the first unused interaction matching the
+// request. Interactions with the same body are preferred, so that parallel
+// creates get their own responses even if they arrive in a different order
+// than when recorded. t.mu must be held.
+func (t *vcrTransport) replay(req *http.Request, url, body string) (*http.Response, error) {
+	var match *Interaction
+	for _, i := range t.cassette.Interactions {
+		if i.used || i.Request.Method != req.Method || i.Request.URL != url {
+			continue
+		}
+		if i.Request.Body == t.scrub(body) {
+			match = i
+			break
+		}
+		if match == nil {
+			match = i
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("cassette %s has no unused interaction for %s %s", t.path, req.Method, url)
+	}
+	match.used = true
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        match.Response.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(match.Response.Body)),
+		ContentLength: int64(len(match.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// record sends the request through base and appends the interaction to the
+// cassette file, so that nothing is lost if the process dies. t.mu must be
+// held.
+func (t *vcrTransport) record(req *http.Request, url string, reqBody []byte) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{}
+	i.Request.Method = req.Method
+	i.Request.URL = url
+	i.Request.Headers = t.scrubHeaders(req.Header)
+	i.Request.Body = t.scrub(string(reqBody))
+	i.Response.StatusCode = resp.StatusCode
+	i.Response.Headers = t.scrubHeaders(resp.Header)
+	i.Response.Body = t.scrub(string(respBody))
+
+	if err := t.append(i); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// append adds i to the cassette file. The cassette is read back and written
+// atomically while holding an exclusive lock on t.path+".lock", so that
+// interactions recorded meanwhile by other processes are kept.
+func (t *vcrTransport) append(i *Interaction) error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	lock, err := os.OpenFile(t.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer lock.Close()
+
+	if err := filelock.Lock(lock); err != nil {
+		return fmt.Errorf("failed to lock cassette %s: %w", t.path, err)
+	}
+	defer func() {
+		_ = filelock.Unlock(lock)
+	}()
+
+	cassette, err := readCassette(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		cassette, err = &Cassette{Version: cassetteVersion}, nil
+	}
+	if err != nil {
+		return err
+	}
+	cassette.Interactions = append(cassette.Interactions, i)
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// scrubHeaders returns a copy of h without credentials. Sensitive headers are
+// kept with a placeholder value so that the cassette shows they were sent.
+func (t *vcrTransport) scrubHeaders(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if t.sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = []string{redacted}
+			continue
+		}
+		for _, s := range v {
+			out[k] = append(out[k], t.scrub(s))
+		}
+	}
+	return out
+}
+
+// scrub replaces every secret in s, and the value of any "token" field if s
+// is a JSON document, with a placeholder.
+func (t *vcrTransport) scrub(s string) string {
+	for _, secret := range t.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	if _, ok := v["token"]; !ok {
+		return s
+	}
+	v["token"] = redacted
+	b, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
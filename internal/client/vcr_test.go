@@ -0,0 +1,193 @@
+package client
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+// replayEndpoint is used by clients replaying cassettes. Nothing listens on
+// it, so any request that is not served from the cassette fails.
+const replayEndpoint = "http://nahcloud.invalid"
+
+func TestVCRRecordReplay(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := nahtest.NewServer(t)
+	srv.InjectStatus("GET /v1/buckets/{id}", http.StatusServiceUnavailable, 1)
+	recorder := NewClient(srv.URL, "secret-token",
+		WithRetryPolicy(testRetryPolicy),
+		WithSensitiveHeader("X-Api-Key", "secret-key"),
+		WithVCR(VCRRecord, cassette),
+	)
+
+	bucket, err := recorder.CreateBucket(t.Context(), "recorded")
+	if err != nil {
+		t.Fatalf("creating bucket: %s", err)
+	}
+	if _, err := recorder.GetBucket(t.Context(), bucket.ID); err != nil {
+		t.Fatalf("reading bucket: %s", err)
+	}
+
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatalf("reading cassette: %s", err)
+	}
+	for _, secret := range []string{"secret-token", "secret-key"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	player := NewClient(replayEndpoint, "", WithRetryPolicy(testRetryPolicy), WithVCR(VCRReplay, cassette))
+	replayed, err := player.CreateBucket(t.Context(), "recorded")
+	if err != nil {
+		t.Fatalf("replaying create: %s", err)
+	}
+	if replayed.ID != bucket.ID || replayed.ETag != bucket.ETag {
+		t.Errorf("expected replayed bucket %+v, got %+v", bucket, replayed)
+	}
+	// The recorded 503 is replayed too, and retried like the original.
+	if _, err := player.GetBucket(t.Context(), bucket.ID); err != nil {
+		t.Errorf("replaying read: %s", err)
+	}
+	if _, err := player.GetBucket(t.Context(), bucket.ID); err == nil || !strings.Contains(err.Error(), "no unused interaction") {
+		t.Errorf("expected an error once the cassette is exhausted, got %v", err)
+	}
+}
+
+func TestVCRRecordShared(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	srv := nahtest.NewServer(t)
+
+	// Two recorders, as in two provider processes, share the cassette.
+	first := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithVCR(VCRRecord, cassette))
+	second := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithVCR(VCRRecord, cassette))
+
+	project, err := first.CreateProject(t.Context(), "first")
+	if err != nil {
+		t.Fatalf("creating project: %s", err)
+	}
+	if _, err := second.CreateProject(t.Context(), "second"); err != nil {
+		t.Fatalf("creating project: %s", err)
+	}
+	if _, err := first.GetProject(t.Context(), project.ID); err != nil {
+		t.Fatalf("reading project: %s", err)
+	}
+
+	const recorders, requests = 4, 10
+	var wg sync.WaitGroup
+	for range recorders {
+		c := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithVCR(VCRRecord, cassette))
+		wg.Go(func() {
+			for range requests {
+				if _, err := c.GetProject(t.Context(), project.ID); err != nil {
+					t.Errorf("reading project: %s", err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	recorded, err := readCassette(cassette)
+	if err != nil {
+		t.Fatalf("reading cassette: %s", err)
+	}
+	if got, want := len(recorded.Interactions), 3+recorders*requests; got != want {
+		t.Errorf("expected %d recorded interactions, got %d", want, got)
+	}
+}
+
+func TestVCRReplayMissingCassette(t *testing.T) {
+	c := NewClient(replayEndpoint, "", WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithVCR(VCRReplay, filepath.Join(t.TempDir(), "missing.json")))
+
+	_, err := c.ListProjects(t.Context(), nil)
+	if err == nil || !strings.Contains(err.Error(), "record it first") {
+		t.Errorf("expected a missing cassette error, got %v", err)
+	}
+}
+
+// TestCassettes replays the interactions in testdata/cassettes, which capture
+// server behaviors worth keeping as regression tests. Re-record them from
+// scratch against the fake server with:
+//
+//	NAH_VCR_MODE=record go test ./internal/client -run TestCassettes
+func TestCassettes(t *testing.T) {
+	tests := map[string]struct {
+		// setup prepares the server before recording.
+		setup func(srv *nahtest.Server)
+		run   func(t *testing.T, c *Client)
+	}{
+		"non_empty_bucket_delete": {
+			run: func(t *testing.T, c *Client) {
+				bucket, err := c.CreateBucket(t.Context(), "cassette-bucket")
+				if err != nil {
+					t.Fatalf("creating bucket: %s", err)
+				}
+				object, err := c.CreateObject(t.Context(), bucket.ID, &CreateObjectRequest{Path: "a.txt", Content: "aGk="})
+				if err != nil {
+					t.Fatalf("creating object: %s", err)
+				}
+				if err := c.DeleteBucket(t.Context(), bucket.ID); !IsConflict(err) {
+					t.Errorf("expected a conflict deleting a non-empty bucket, got %v", err)
+				}
+				if err := c.DeleteObject(t.Context(), bucket.ID, object.ID); err != nil {
+					t.Fatalf("deleting object: %s", err)
+				}
+				if err := c.DeleteBucket(t.Context(), bucket.ID); err != nil {
+					t.Errorf("deleting empty bucket: %s", err)
+				}
+			},
+		},
+		"throttled_update": {
+			setup: func(srv *nahtest.Server) {
+				srv.InjectStatus("PATCH /v1/projects/{id}", http.StatusTooManyRequests, 2)
+			},
+			run: func(t *testing.T, c *Client) {
+				project, err := c.CreateProject(t.Context(), "cassette-project")
+				if err != nil {
+					t.Fatalf("creating project: %s", err)
+				}
+				updated, err := c.UpdateProject(t.Context(), project.ID, "cassette-project-2", IfMatch(project.ETag))
+				if err != nil {
+					t.Fatalf("expected the update to succeed after being throttled, got %s", err)
+				}
+				if updated.Name != "cassette-project-2" {
+					t.Errorf("unexpected name %q", updated.Name)
+				}
+				_, err = c.UpdateProject(t.Context(), project.ID, "cassette-project-3", IfMatch(project.ETag))
+				if !IsPreconditionFailed(err) {
+					t.Errorf("expected a stale ETag to be rejected, got %v", err)
+				}
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cassette := filepath.Join("testdata", "cassettes", name+".json")
+
+			endpoint, mode := replayEndpoint, VCRReplay
+			if os.Getenv("NAH_VCR_MODE") == string(VCRRecord) {
+				srv := nahtest.NewServer(t)
+				if tt.setup != nil {
+					tt.setup(srv)
+				}
+				endpoint, mode = srv.URL, VCRRecord
+				if err := os.Remove(cassette); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					t.Fatal(err)
+				}
+			}
+
+			tt.run(t, NewClient(endpoint, "", WithRetryPolicy(testRetryPolicy), WithVCR(mode, cassette)))
+		})
+	}
+}
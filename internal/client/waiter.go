@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// DefaultPollInterval is the delay between the first two polls of a
+	// Waiter when PollInterval is zero.
+	DefaultPollInterval = time.Second
+
+	// DefaultMaxPollInterval caps the delay between two polls of a Waiter
+	// when MaxPollInterval is zero.
+	DefaultMaxPollInterval = 10 * time.Second
+)
+
+// Waiter polls an object until it reaches one of the Target states.
+type Waiter[T any] struct {
+	// Refresh fetches the object and returns it along with its current
+	// state. Errors end the wait; transient ones are already retried by the
+	// client.
+	Refresh func(ctx context.Context) (T, string, error)
+
+	// Target are the states that end the wait successfully.
+	Target []string
+
+	// Pending are the states the object may pass through on its way to a
+	// target state. Any other state ends the wait with an
+	// *UnexpectedStateError.
+	Pending []string
+
+	// Timeout bounds the whole wait. Zero means waiting until ctx is done.
+	Timeout time.Duration
+
+	// PollInterval is the delay between the first two polls. It doubles
+	// after every poll, up to MaxPollInterval.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+}
+
+// UnexpectedStateError is returned by Wait when the object reaches a state
+// that is neither pending nor a target.
+type UnexpectedStateError struct {
+	State   string
+	Target  []string
+	Pending []string
+}
+
+func (e *UnexpectedStateError) Error() string {
+	return fmt.Sprintf("unexpected state %q while waiting for %s", e.State, formatStates(e.Target))
+}
+
+// WaitTimeoutError is returned by Wait when the object has not reached a
+// target state before the timeout or the deadline of the context.
+type WaitTimeoutError struct {
+	LastState string
+	Target    []string
+	Err       error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("timed out waiting for %s, last state was %q: %s", formatStates(e.Target), e.LastState, e.Err)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Wait polls until the object reaches a target state and returns it. The
+// first poll happens immediately.
+func (w *Waiter[T]) Wait(ctx context.Context) (T, error) {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	interval := w.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	maxInterval := w.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxPollInterval
+	}
+
+	var lastState string
+	for attempt := 1; ; attempt++ {
+		v, state, err := w.Refresh(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return v, &WaitTimeoutError{LastState: lastState, Target: w.Target, Err: err}
+			}
+			return v, err
+		}
+		lastState = state
+
+		switch {
+		case slices.Contains(w.Target, state):
+			return v, nil
+		case !slices.Contains(w.Pending, state):
+			return v, &UnexpectedStateError{State: state, Target: w.Target, Pending: w.Pending}
+		}
+
+		tflog.Debug(ctx, "Waiting for state", map[string]interface{}{
+			"state":   state,
+			"target":  w.Target,
+			"attempt": attempt,
+			"delay":   interval.String(),
+		})
+		if err := sleep(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return v, &WaitTimeoutError{LastState: lastState, Target: w.Target, Err: err}
+			}
+			return v, err
+		}
+		interval = min(interval*2, maxInterval)
+	}
+}
+
+// formatStates formats states for error messages, e.g. `"running" or
+// "stopped"`.
+func formatStates(states []string) string {
+	quoted := make([]string, len(states))
+	for i, s := range states {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, " or ")
+}
+
+// Instance statuses reported by NahCloud. Only InstanceRunning and
+// InstanceStopped can be requested; the others are transitional.
+const (
+	InstanceRunning  = "running"
+	InstanceStopped  = "stopped"
+	InstanceStarting = "starting"
+	InstanceStopping = "stopping"
+)
+
+// instancePendingStatuses are the transitional statuses an instance may
+// report on its way to each requestable status.
+var instancePendingStatuses = map[string][]string{
+	InstanceRunning: {InstanceStarting},
+	InstanceStopped: {InstanceStopping},
+}
+
+// WaitForInstanceStatus polls the instance until it reports status, which
+// is InstanceRunning or InstanceStopped, and returns it. from is the status
+// the instance had before it was asked to change, if any: NahCloud may keep
+// reporting it for a moment, so it is pending too.
+func (c *Client) WaitForInstanceStatus(ctx context.Context, id, from, status string) (*Instance, error) {
+	pending := instancePendingStatuses[status]
+	if from != "" && from != status {
+		pending = append(slices.Clip(pending), from)
+	}
+
+	w := &Waiter[*Instance]{
+		Refresh: func(ctx context.Context) (*Instance, string, error) {
+			instance, err := c.GetInstance(ctx, id)
+			if err != nil {
+				return nil, "", err
+			}
+			return instance, instance.Status, nil
+		},
+		Target:          []string{status},
+		Pending:         pending,
+		PollInterval:    c.pollInterval,
+		MaxPollInterval: c.maxPollInterval,
+	}
+	return w.Wait(ctx)
+}
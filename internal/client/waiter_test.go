@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hypertf/terraform-provider-nah/internal/nahcloud"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+// testWaiter returns a waiter polling a fake object that reports each of
+// states in turn, and then the last one forever.
+func testWaiter(states ...string) (*Waiter[int], *int) {
+	polls := new(int)
+	return &Waiter[int]{
+		Refresh: func(ctx context.Context) (int, string, error) {
+			state := states[min(*polls, len(states)-1)]
+			*polls++
+			return *polls, state, nil
+		},
+		Target:       []string{"ready"},
+		Pending:      []string{"creating", "configuring"},
+		PollInterval: time.Millisecond,
+	}, polls
+}
+
+func TestWaiter(t *testing.T) {
+	w, polls := testWaiter("creating", "configuring", "ready")
+
+	v, err := w.Wait(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v != 3 || *polls != 3 {
+		t.Errorf("expected the object of the third poll, got %d after %d polls", v, *polls)
+	}
+}
+
+func TestWaiterUnexpectedState(t *testing.T) {
+	w, _ := testWaiter("creating", "failed")
+
+	_, err := w.Wait(t.Context())
+	var stateErr *UnexpectedStateError
+	if !errors.As(err, &stateErr) || stateErr.State != "failed" {
+		t.Errorf("expected an unexpected state error for \"failed\", got %v", err)
+	}
+}
+
+func TestWaiterTimeout(t *testing.T) {
+	w, _ := testWaiter("creating")
+	w.Timeout = 20 * time.Millisecond
+
+	_, err := w.Wait(t.Context())
+	var timeoutErr *WaitTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.LastState != "creating" {
+		t.Fatalf("expected a timeout error in state \"creating\", got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error to wrap context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWaiterBackoff(t *testing.T) {
+	w, polls := testWaiter("creating")
+	w.PollInterval = 10 * time.Millisecond
+	w.MaxPollInterval = 20 * time.Millisecond
+	w.Timeout = 100 * time.Millisecond
+
+	_, _ = w.Wait(t.Context())
+	// Polls at 0, 10, 30, 50, 70 and 90ms.
+	if *polls < 4 || *polls > 7 {
+		t.Errorf("expected about 6 polls with a capped backoff, got %d", *polls)
+	}
+}
+
+func TestWaitForInstanceStatus(t *testing.T) {
+	srv := nahtest.NewServer(t)
+	c := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithPollInterval(time.Millisecond, time.Millisecond))
+	project, err := c.CreateProject(t.Context(), "waiter")
+	if err != nil {
+		t.Fatalf("creating project: %s", err)
+	}
+
+	srv.SimulateInstanceTransitions(2)
+	instance, err := c.CreateInstance(t.Context(), &CreateInstanceRequest{
+		ProjectID: project.ID,
+		Name:      "waiter",
+		CPU:       1,
+		MemoryMB:  512,
+		Image:     "ubuntu:24.04",
+		Status:    InstanceStopped,
+	})
+	if err != nil {
+		t.Fatalf("creating instance: %s", err)
+	}
+	if instance.Status != InstanceStopping {
+		t.Fatalf("expected the instance to be created %q, got %q", InstanceStopping, instance.Status)
+	}
+
+	instance, err = c.WaitForInstanceStatus(t.Context(), instance.ID, "", InstanceStopped)
+	if err != nil {
+		t.Fatalf("waiting for instance: %s", err)
+	}
+	if instance.Status != InstanceStopped {
+		t.Errorf("expected status %q, got %q", InstanceStopped, instance.Status)
+	}
+
+	// A stopping instance is not on its way to running.
+	srv.SimulateInstanceTransitions(1)
+	stopped := InstanceStopped
+	if _, err := c.UpdateInstance(t.Context(), instance.ID, &UpdateInstanceRequest{Status: &stopped}); err != nil {
+		t.Fatalf("updating instance: %s", err)
+	}
+	_, err = c.WaitForInstanceStatus(t.Context(), instance.ID, InstanceStopped, InstanceRunning)
+	var stateErr *UnexpectedStateError
+	if !errors.As(err, &stateErr) || stateErr.State != InstanceStopping {
+		t.Errorf("expected an unexpected state error for %q, got %v", InstanceStopping, err)
+	}
+}
+
+func TestWaitForInstanceStatusFrom(t *testing.T) {
+	srv := nahtest.NewServer(t)
+	c := NewClient(srv.URL, "", WithRetryPolicy(testRetryPolicy), WithPollInterval(time.Millisecond, time.Millisecond))
+	project, err := c.CreateProject(t.Context(), "waiter")
+	if err != nil {
+		t.Fatalf("creating project: %s", err)
+	}
+	instance, err := c.CreateInstance(t.Context(), &CreateInstanceRequest{
+		ProjectID: project.ID,
+		Name:      "waiter",
+		CPU:       1,
+		MemoryMB:  512,
+		Image:     "ubuntu:24.04",
+	})
+	if err != nil {
+		t.Fatalf("creating instance: %s", err)
+	}
+
+	// Without the status it is changing from, a running instance is not on
+	// its way to stopped.
+	_, err = c.WaitForInstanceStatus(t.Context(), instance.ID, "", InstanceStopped)
+	var stateErr *UnexpectedStateError
+	if !errors.As(err, &stateErr) || stateErr.State != InstanceRunning {
+		t.Errorf("expected an unexpected state error for %q, got %v", InstanceRunning, err)
+	}
+
+	// A backend may keep reporting the previous status for a moment after
+	// the change was requested.
+	time.AfterFunc(20*time.Millisecond, func() {
+		srv.UpdateInstance(instance.ID, func(i *nahcloud.Instance) { i.Status = InstanceStopped })
+	})
+	instance, err = c.WaitForInstanceStatus(t.Context(), instance.ID, InstanceRunning, InstanceStopped)
+	if err != nil {
+		t.Fatalf("waiting for instance: %s", err)
+	}
+	if instance.Status != InstanceStopped {
+		t.Errorf("expected status %q, got %q", InstanceStopped, instance.Status)
+	}
+}
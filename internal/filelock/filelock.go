@@ -0,0 +1,3 @@
+// Package filelock provides exclusive locks on files, used to serialize
+// read-modify-write cycles of files shared between provider processes.
+package filelock
@@ -0,0 +1,48 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json.lock")
+	open := func() *os.File {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+	first, second := open(), open()
+
+	if err := Lock(first); err != nil {
+		t.Fatalf("locking: %s", err)
+	}
+	locked := make(chan error, 1)
+	go func() { locked <- Lock(second) }()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the second lock to wait for the first to be released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := Unlock(first); err != nil {
+		t.Fatalf("unlocking: %s", err)
+	}
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatalf("locking: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second lock once the first was released")
+	}
+	if err := Unlock(second); err != nil {
+		t.Fatalf("unlocking: %s", err)
+	}
+}
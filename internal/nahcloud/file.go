@@ -0,0 +1,104 @@
+package nahcloud
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hypertf/terraform-provider-nah/internal/filelock"
+)
+
+// NewFileServer returns a Server whose state is stored in the JSON file at
+// path, so that it persists between processes. The file is read before and
+// written after every request while holding an exclusive lock on
+// path+".lock", which lets several processes share it safely.
+func NewFileServer(path string) *Server {
+	s := NewServer()
+	s.path = path
+	return s
+}
+
+// withFileState loads the state from the backing file, calls fn, and writes
+// the state back if write is set, all while holding the file lock.
+func (s *Server) withFileState(write bool, fn func()) error {
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create state directory: %w", err)
+		}
+	}
+
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer lock.Close()
+
+	if err := filelock.Lock(lock); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	defer func() {
+		_ = filelock.Unlock(lock)
+	}()
+
+	state, err := readStateFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.state = state
+
+	fn()
+
+	if !write {
+		return nil
+	}
+	return writeStateFile(s.path, s.state)
+}
+
+func readStateFile(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	state := &State{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to decode state file %s: %w", path, err)
+		}
+	}
+	state.init()
+	return state, nil
+}
+
+// writeStateFile replaces the state file atomically, so that a crash never
+// leaves it half-written.
+func writeStateFile(path string, state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
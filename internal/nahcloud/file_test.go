@@ -0,0 +1,143 @@
+package nahcloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serve sends a request to h and returns the response, decoding its JSON
+// body into v unless v is nil.
+func serve(t *testing.T, h http.Handler, method, path string, body interface{}, header http.Header, v interface{}) *http.Response {
+	t.Helper()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatalf("encoding request: %s", err)
+		}
+	}
+	req := httptest.NewRequestWithContext(t.Context(), method, path, &reqBody)
+	for k, values := range header {
+		req.Header[k] = values
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	resp := rec.Result()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: decoding response: %s", method, path, err)
+		}
+	}
+	return resp
+}
+
+func TestFileServerShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	a, b := NewFileServer(path), NewFileServer(path)
+
+	var project Project
+	if resp := serve(t, a, http.MethodPost, "/v1/projects", map[string]string{"name": "shared"}, nil, &project); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 creating a project, got %d", resp.StatusCode)
+	}
+	if resp := serve(t, b, http.MethodGet, "/v1/projects/"+project.ID, nil, nil, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the project to be visible to another server, got %d", resp.StatusCode)
+	}
+
+	// A server opened later, as by another process, sees the same data.
+	if resp := serve(t, NewFileServer(path), http.MethodDelete, "/v1/projects/"+project.ID, nil, nil, nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204 deleting the project, got %d", resp.StatusCode)
+	}
+	if resp := serve(t, a, http.MethodGet, "/v1/projects/"+project.ID, nil, nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the deletion to be visible, got %d", resp.StatusCode)
+	}
+}
+
+func TestFileServerConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	servers := []*Server{NewFileServer(path), NewFileServer(path), NewFileServer(path)}
+
+	const writes = 20
+	var wg sync.WaitGroup
+	for i := range writes {
+		wg.Go(func() {
+			h := servers[i%len(servers)]
+			body := map[string]string{"path": fmt.Sprintf("/concurrent/%d", i), "value": "v"}
+			if resp := serve(t, h, http.MethodPost, "/v1/metadata", body, nil, nil); resp.StatusCode != http.StatusCreated {
+				t.Errorf("write %d: expected 201, got %d", i, resp.StatusCode)
+			}
+		})
+	}
+	wg.Wait()
+
+	// No write was lost to another server overwriting the file with a stale
+	// state.
+	state, err := readStateFile(path)
+	if err != nil {
+		t.Fatalf("reading state file: %s", err)
+	}
+	if got := len(state.Metadata); got != writes {
+		t.Errorf("expected %d metadata entries, got %d", writes, got)
+	}
+}
+
+func TestFileServerInvalidState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := serve(t, NewFileServer(path), http.MethodGet, "/v1/projects", nil, nil, nil)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a corrupt state file, got %d", resp.StatusCode)
+	}
+}
+
+func TestFileServerIdempotentResponses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	header := http.Header{"Idempotency-Key": []string{"key-1"}}
+	body := map[string]string{"path": "/app/db", "value": "metadata-s3cret"}
+
+	var created Metadata
+	if resp := serve(t, NewFileServer(path), http.MethodPost, "/v1/metadata", body, header, &created); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	// The value is stored once, with the metadata, and not again with the
+	// recorded response.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "metadata-s3cret"); n != 1 {
+		t.Errorf("expected the value to be stored once, found %d times:\n%s", n, data)
+	}
+
+	var replayed Metadata
+	resp := serve(t, NewFileServer(path), http.MethodPost, "/v1/metadata", body, header, &replayed)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected a replayed 201, got %d with headers %v", resp.StatusCode, resp.Header)
+	}
+	if replayed.ID != created.ID || replayed.Value != "metadata-s3cret" || resp.Header.Get("ETag") != etag(created.Version) {
+		t.Errorf("expected the created metadata %+v, got %+v", created, replayed)
+	}
+
+	// Errors are replayed as recorded.
+	header.Set("Idempotency-Key", "key-2")
+	if resp := serve(t, NewFileServer(path), http.MethodPost, "/v1/metadata", body, header, nil); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate path, got %d", resp.StatusCode)
+	}
+	serve(t, NewFileServer(path), http.MethodDelete, "/v1/metadata/"+created.ID, nil, nil, nil)
+	resp = serve(t, NewFileServer(path), http.MethodPost, "/v1/metadata", body, header, nil)
+	if resp.StatusCode != http.StatusConflict || resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected the replayed 409, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,754 @@
+package nahcloud
+
+import (
+	"encoding/base64"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var bucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// Projects
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	p, err := paginate(r, slices.Collect(maps.Values(s.state.Projects)),
+		func(v *Project) time.Time { return v.CreatedAt }, func(v *Project) string { return v.ID })
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p, 0)
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if err := validateName("project", req.Name); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	now := s.now()
+	project := &Project{ID: newID("proj"), Name: req.Name, CreatedAt: now, UpdatedAt: now, Version: 1}
+	s.state.Projects[project.ID] = project
+	writeJSON(w, http.StatusCreated, project, project.Version)
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("project", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, project, project.Version)
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("project", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, project.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	var req struct {
+		Name *string `json:"name"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if req.Name != nil {
+		if err := validateName("project", *req.Name); err != nil {
+			writeErr(w, err)
+			return
+		}
+		project.Name = *req.Name
+	}
+
+	project.UpdatedAt = s.now()
+	project.Version++
+	writeJSON(w, http.StatusOK, project, project.Version)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := s.state.Projects[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("project", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, project.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	for _, instance := range s.state.Instances {
+		if instance.ProjectID == project.ID {
+			writeErr(w, errConflict("project %q still has instances", project.ID))
+			return
+		}
+	}
+
+	delete(s.state.Projects, project.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Instances
+
+func (s *Server) listInstances(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Query().Get("project_id")
+
+	var instances []*Instance
+	for _, instance := range s.state.Instances {
+		if projectID == "" || instance.ProjectID == projectID {
+			instances = append(instances, instance)
+		}
+	}
+
+	p, err := paginate(r, instances,
+		func(v *Instance) time.Time { return v.CreatedAt }, func(v *Instance) string { return v.ID })
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p, 0)
+}
+
+func (s *Server) createInstance(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ProjectID string `json:"project_id"`
+		Name      string `json:"name"`
+		CPU       int    `json:"cpu"`
+		MemoryMB  int    `json:"memory_mb"`
+		Image     string `json:"image"`
+		Status    string `json:"status"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if req.Status == "" {
+		req.Status = "running"
+	}
+
+	instance := &Instance{
+		ProjectID: req.ProjectID,
+		Name:      req.Name,
+		CPU:       req.CPU,
+		MemoryMB:  req.MemoryMB,
+		Image:     req.Image,
+		Status:    req.Status,
+	}
+	if err := validateInstance(instance); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if _, ok := s.state.Projects[req.ProjectID]; !ok {
+		writeErr(w, errNotFound("project", req.ProjectID))
+		return
+	}
+
+	now := s.now()
+	instance.ID = newID("inst")
+	instance.CreatedAt = now
+	instance.UpdatedAt = now
+	instance.Version = 1
+	s.state.Instances[instance.ID] = instance
+	writeJSON(w, http.StatusCreated, instance, instance.Version)
+}
+
+func (s *Server) getInstance(w http.ResponseWriter, r *http.Request) {
+	instance, ok := s.state.Instances[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("instance", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, instance, instance.Version)
+}
+
+func (s *Server) updateInstance(w http.ResponseWriter, r *http.Request) {
+	instance, ok := s.state.Instances[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("instance", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, instance.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	var req struct {
+		Name     *string `json:"name"`
+		CPU      *int    `json:"cpu"`
+		MemoryMB *int    `json:"memory_mb"`
+		Image    *string `json:"image"`
+		Status   *string `json:"status"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	updated := *instance
+	if req.Name != nil {
+		updated.Name = *req.Name
+	}
+	if req.CPU != nil {
+		updated.CPU = *req.CPU
+	}
+	if req.MemoryMB != nil {
+		updated.MemoryMB = *req.MemoryMB
+	}
+	if req.Image != nil {
+		updated.Image = *req.Image
+	}
+	if req.Status != nil {
+		updated.Status = *req.Status
+	}
+	if err := validateInstance(&updated); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	updated.UpdatedAt = s.now()
+	updated.Version++
+	*instance = updated
+	writeJSON(w, http.StatusOK, instance, instance.Version)
+}
+
+func (s *Server) deleteInstance(w http.ResponseWriter, r *http.Request) {
+	instance, ok := s.state.Instances[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("instance", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, instance.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	delete(s.state.Instances, instance.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateInstance(instance *Instance) error {
+	if instance.ProjectID == "" {
+		return errInvalid("project_id is required")
+	}
+	if err := validateName("instance", instance.Name); err != nil {
+		return err
+	}
+	if instance.Image == "" {
+		return errInvalid("image is required")
+	}
+	if instance.CPU < 1 || instance.CPU > 64 {
+		return errInvalid("cpu must be between 1 and 64, got %d", instance.CPU)
+	}
+	if instance.MemoryMB < 128 || instance.MemoryMB > 262144 {
+		return errInvalid("memory_mb must be between 128 and 262144, got %d", instance.MemoryMB)
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return errInvalid("status must be \"running\" or \"stopped\", got %q", instance.Status)
+	}
+	return nil
+}
+
+// Metadata
+
+func (s *Server) listMetadata(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	var entries []*Metadata
+	for _, metadata := range s.state.Metadata {
+		if strings.HasPrefix(metadata.Path, prefix) {
+			entries = append(entries, metadata)
+		}
+	}
+
+	p, err := paginate(r, entries,
+		func(v *Metadata) time.Time { return v.CreatedAt }, func(v *Metadata) string { return v.ID })
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p, 0)
+}
+
+func (s *Server) createMetadata(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path  string `json:"path"`
+		Value string `json:"value"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if err := s.validateMetadataPath(req.Path, ""); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	now := s.now()
+	metadata := &Metadata{ID: newID("meta"), Path: req.Path, Value: req.Value, CreatedAt: now, UpdatedAt: now, Version: 1}
+	s.state.Metadata[metadata.ID] = metadata
+	writeJSON(w, http.StatusCreated, metadata, metadata.Version)
+}
+
+func (s *Server) getMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := s.state.Metadata[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("metadata", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, metadata, metadata.Version)
+}
+
+func (s *Server) updateMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := s.state.Metadata[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("metadata", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, metadata.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	var req struct {
+		Path  *string `json:"path"`
+		Value *string `json:"value"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if req.Path != nil {
+		if err := s.validateMetadataPath(*req.Path, metadata.ID); err != nil {
+			writeErr(w, err)
+			return
+		}
+		metadata.Path = *req.Path
+	}
+	if req.Value != nil {
+		metadata.Value = *req.Value
+	}
+
+	metadata.UpdatedAt = s.now()
+	metadata.Version++
+	writeJSON(w, http.StatusOK, metadata, metadata.Version)
+}
+
+func (s *Server) deleteMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := s.state.Metadata[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("metadata", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, metadata.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	delete(s.state.Metadata, metadata.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateMetadataPath checks that path is a well-formed hierarchical path,
+// such as /config/app/setting, not used by any entry other than selfID.
+func (s *Server) validateMetadataPath(path, selfID string) error {
+	if !strings.HasPrefix(path, "/") || len(path) < 2 {
+		return errInvalid("path must start with \"/\" and name at least one segment, got %q", path)
+	}
+	if strings.HasSuffix(path, "/") || strings.Contains(path, "//") {
+		return errInvalid("path must not contain empty segments, got %q", path)
+	}
+	for _, metadata := range s.state.Metadata {
+		if metadata.Path == path && metadata.ID != selfID {
+			return errConflict("metadata path %q already exists", path)
+		}
+	}
+	return nil
+}
+
+// Buckets
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request) {
+	p, err := paginate(r, slices.Collect(maps.Values(s.state.Buckets)),
+		func(v *Bucket) time.Time { return v.CreatedAt }, func(v *Bucket) string { return v.ID })
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p, 0)
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if err := s.validateBucketName(req.Name, ""); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	now := s.now()
+	bucket := &Bucket{ID: newID("bkt"), Name: req.Name, CreatedAt: now, UpdatedAt: now, Version: 1}
+	s.state.Buckets[bucket.ID] = bucket
+	writeJSON(w, http.StatusCreated, bucket, bucket.Version)
+}
+
+func (s *Server) getBucket(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.state.Buckets[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("bucket", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, bucket, bucket.Version)
+}
+
+func (s *Server) updateBucket(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.state.Buckets[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("bucket", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, bucket.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	var req struct {
+		Name *string `json:"name"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if req.Name != nil {
+		if err := s.validateBucketName(*req.Name, bucket.ID); err != nil {
+			writeErr(w, err)
+			return
+		}
+		bucket.Name = *req.Name
+	}
+
+	bucket.UpdatedAt = s.now()
+	bucket.Version++
+	writeJSON(w, http.StatusOK, bucket, bucket.Version)
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.state.Buckets[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("bucket", r.PathValue("id")))
+		return
+	}
+	if err := checkIfMatch(r, bucket.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	for _, object := range s.state.Objects {
+		if object.BucketID == bucket.ID {
+			writeErr(w, errConflict("bucket %q is not empty", bucket.ID))
+			return
+		}
+	}
+
+	delete(s.state.Buckets, bucket.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateBucketName checks that name is a valid bucket name not used by any
+// bucket other than selfID. Bucket names are globally unique.
+func (s *Server) validateBucketName(name, selfID string) error {
+	if !bucketNameRegexp.MatchString(name) {
+		return errInvalid("bucket name must be 3-63 lowercase letters, digits, dots or hyphens, got %q", name)
+	}
+	for _, bucket := range s.state.Buckets {
+		if bucket.Name == name && bucket.ID != selfID {
+			return errConflict("bucket name %q is already taken", name)
+		}
+	}
+	return nil
+}
+
+// Objects
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request) {
+	bucketID := r.PathValue("bucket_id")
+	if _, ok := s.state.Buckets[bucketID]; !ok {
+		writeErr(w, errNotFound("bucket", bucketID))
+		return
+	}
+	prefix := r.URL.Query().Get("prefix")
+
+	var objects []*Object
+	for _, object := range s.state.Objects {
+		if object.BucketID == bucketID && strings.HasPrefix(object.Path, prefix) {
+			objects = append(objects, object)
+		}
+	}
+
+	p, err := paginate(r, objects,
+		func(v *Object) time.Time { return v.CreatedAt }, func(v *Object) string { return v.ID })
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p, 0)
+}
+
+func (s *Server) createObject(w http.ResponseWriter, r *http.Request) {
+	bucketID := r.PathValue("bucket_id")
+	if _, ok := s.state.Buckets[bucketID]; !ok {
+		writeErr(w, errNotFound("bucket", bucketID))
+		return
+	}
+
+	var req struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if err := s.validateObject(bucketID, req.Path, req.Content, ""); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	now := s.now()
+	object := &Object{ID: newID("obj"), BucketID: bucketID, Path: req.Path, Content: req.Content, CreatedAt: now, UpdatedAt: now, Version: 1}
+	s.state.Objects[object.ID] = object
+	writeJSON(w, http.StatusCreated, object, object.Version)
+}
+
+// lookupObject returns the object addressed by the request path.
+func (s *Server) lookupObject(r *http.Request) (*Object, error) {
+	bucketID, id := r.PathValue("bucket_id"), r.PathValue("id")
+	if _, ok := s.state.Buckets[bucketID]; !ok {
+		return nil, errNotFound("bucket", bucketID)
+	}
+	object, ok := s.state.Objects[id]
+	if !ok || object.BucketID != bucketID {
+		return nil, errNotFound("object", id)
+	}
+	return object, nil
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request) {
+	object, err := s.lookupObject(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, object, object.Version)
+}
+
+func (s *Server) updateObject(w http.ResponseWriter, r *http.Request) {
+	object, err := s.lookupObject(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if err := checkIfMatch(r, object.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	var req struct {
+		Path    *string `json:"path"`
+		Content *string `json:"content"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	path, content := object.Path, object.Content
+	if req.Path != nil {
+		path = *req.Path
+	}
+	if req.Content != nil {
+		content = *req.Content
+	}
+	if err := s.validateObject(object.BucketID, path, content, object.ID); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	object.Path = path
+	object.Content = content
+	object.UpdatedAt = s.now()
+	object.Version++
+	writeJSON(w, http.StatusOK, object, object.Version)
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request) {
+	object, err := s.lookupObject(r)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if err := checkIfMatch(r, object.Version); err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	delete(s.state.Objects, object.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateObject checks the path and base64 content of an object, and that
+// no object other than selfID in the bucket uses the path.
+func (s *Server) validateObject(bucketID, path, content, selfID string) error {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return errInvalid("object path must be non-empty and must not start with \"/\", got %q", path)
+	}
+	if _, err := base64.StdEncoding.DecodeString(content); err != nil {
+		return errInvalid("content must be base64-encoded: %s", err)
+	}
+	for _, object := range s.state.Objects {
+		if object.BucketID == bucketID && object.Path == path && object.ID != selfID {
+			return errConflict("object path %q already exists in bucket %q", path, bucketID)
+		}
+	}
+	return nil
+}
+
+// Tokens
+
+// tokenScopes are the scopes a token can be granted. Tokens get all of them
+// unless the request restricts them.
+var tokenScopes = []string{"read", "write"}
+
+const (
+	defaultTokenTTL = time.Hour
+	minTokenTTL     = time.Minute
+	maxTokenTTL     = 24 * time.Hour
+)
+
+func (s *Server) createToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Scopes     []string `json:"scopes"`
+		TTLSeconds int      `json:"ttl_seconds"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		req.Scopes = tokenScopes
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(tokenScopes, scope) {
+			writeErr(w, errInvalid("scope must be one of %q, got %q", tokenScopes, scope))
+			return
+		}
+	}
+	ttl, err := tokenTTL(req.TTLSeconds)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	s.pruneTokens()
+	now := s.now()
+	token := &Token{
+		ID:        newID("tok"),
+		Token:     "nah_" + randomHex(20),
+		Scopes:    slices.Clone(req.Scopes),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	s.state.Tokens[token.ID] = token
+	writeJSON(w, http.StatusCreated, token, 0)
+}
+
+func (s *Server) renewToken(w http.ResponseWriter, r *http.Request) {
+	s.pruneTokens()
+	token, ok := s.state.Tokens[r.PathValue("id")]
+	if !ok {
+		writeErr(w, errNotFound("token", r.PathValue("id")))
+		return
+	}
+
+	var req struct {
+		TTLSeconds int `json:"ttl_seconds"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeErr(w, err)
+		return
+	}
+	ttl, err := tokenTTL(req.TTLSeconds)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+
+	token.ExpiresAt = s.now().Add(ttl)
+	renewed := *token
+	renewed.Token = ""
+	writeJSON(w, http.StatusOK, &renewed, 0)
+}
+
+func (s *Server) revokeToken(w http.ResponseWriter, r *http.Request) {
+	s.pruneTokens()
+	if _, ok := s.state.Tokens[r.PathValue("id")]; !ok {
+		writeErr(w, errNotFound("token", r.PathValue("id")))
+		return
+	}
+
+	delete(s.state.Tokens, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tokenTTL validates the requested lifetime of a token, in seconds. Zero
+// selects the default.
+func tokenTTL(seconds int) (time.Duration, error) {
+	if seconds == 0 {
+		return defaultTokenTTL, nil
+	}
+	ttl := time.Duration(seconds) * time.Second
+	if ttl < minTokenTTL || ttl > maxTokenTTL {
+		return 0, errInvalid("ttl_seconds must be between %d and %d, got %d",
+			int(minTokenTTL.Seconds()), int(maxTokenTTL.Seconds()), seconds)
+	}
+	return ttl, nil
+}
+
+// pruneTokens removes expired tokens.
+func (s *Server) pruneTokens() {
+	now := s.now()
+	for id, token := range s.state.Tokens {
+		if !token.ExpiresAt.After(now) {
+			delete(s.state.Tokens, id)
+		}
+	}
+}
+
+func validateName(kind, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errInvalid("%s name is required", kind)
+	}
+	if len(name) > 255 {
+		return errInvalid("%s name must be at most 255 characters", kind)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+// Package nahcloud is an in-process implementation of the NahCloud /v1 API.
+// It backs the memory:// endpoint of the provider and the fake servers used
+// in tests.
+package nahcloud
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+
+	// idempotencyTTL is how long responses are kept for replay.
+	idempotencyTTL = 24 * time.Hour
+)
+
+// Server serves the NahCloud /v1 API from a State. It is safe for concurrent
+// use; requests are processed one at a time.
+type Server struct {
+	mu    sync.Mutex
+	state *State
+	mux   *http.ServeMux
+	now   func() time.Time
+
+	// path is the file the state is persisted to, if any.
+	path string
+}
+
+// NewServer returns a Server with an empty state.
+func NewServer() *Server {
+	s := &Server{
+		state: NewState(),
+		now:   func() time.Time { return time.Now().UTC() },
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux = http.NewServeMux()
+
+	s.mux.HandleFunc("GET /v1/projects", s.listProjects)
+	s.mux.HandleFunc("POST /v1/projects", s.createProject)
+	s.mux.HandleFunc("GET /v1/projects/{id}", s.getProject)
+	s.mux.HandleFunc("PATCH /v1/projects/{id}", s.updateProject)
+	s.mux.HandleFunc("DELETE /v1/projects/{id}", s.deleteProject)
+
+	s.mux.HandleFunc("GET /v1/instances", s.listInstances)
+	s.mux.HandleFunc("POST /v1/instances", s.createInstance)
+	s.mux.HandleFunc("GET /v1/instances/{id}", s.getInstance)
+	s.mux.HandleFunc("PATCH /v1/instances/{id}", s.updateInstance)
+	s.mux.HandleFunc("DELETE /v1/instances/{id}", s.deleteInstance)
+
+	s.mux.HandleFunc("GET /v1/metadata", s.listMetadata)
+	s.mux.HandleFunc("POST /v1/metadata", s.createMetadata)
+	s.mux.HandleFunc("GET /v1/metadata/{id}", s.getMetadata)
+	s.mux.HandleFunc("PATCH /v1/metadata/{id}", s.updateMetadata)
+	s.mux.HandleFunc("DELETE /v1/metadata/{id}", s.deleteMetadata)
+
+	s.mux.HandleFunc("GET /v1/buckets", s.listBuckets)
+	s.mux.HandleFunc("POST /v1/buckets", s.createBucket)
+	s.mux.HandleFunc("GET /v1/buckets/{id}", s.getBucket)
+	s.mux.HandleFunc("PATCH /v1/buckets/{id}", s.updateBucket)
+	s.mux.HandleFunc("DELETE /v1/buckets/{id}", s.deleteBucket)
+
+	s.mux.HandleFunc("GET /v1/bucket/{bucket_id}/objects", s.listObjects)
+	s.mux.HandleFunc("POST /v1/bucket/{bucket_id}/objects", s.createObject)
+	s.mux.HandleFunc("GET /v1/bucket/{bucket_id}/objects/{id}", s.getObject)
+	s.mux.HandleFunc("PATCH /v1/bucket/{bucket_id}/objects/{id}", s.updateObject)
+	s.mux.HandleFunc("DELETE /v1/bucket/{bucket_id}/objects/{id}", s.deleteObject)
+
+	s.mux.HandleFunc("POST /v1/tokens", s.createToken)
+	s.mux.HandleFunc("POST /v1/tokens/{id}/renew", s.renewToken)
+	s.mux.HandleFunc("DELETE /v1/tokens/{id}", s.revokeToken)
+}
+
+// Update calls fn with exclusive access to the server state, e.g. to modify
+// objects out-of-band in tests.
+func (s *Server) Update(fn func(*State)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		fn(s.state)
+		return nil
+	}
+	return s.withFileState(true, func() { fn(s.state) })
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		s.serve(w, r)
+		return
+	}
+
+	// Buffer the response so that a failure to persist the state can still
+	// be reported as an error.
+	buf := newResponseBuffer()
+	if err := s.withFileState(r.Method != http.MethodGet, func() { s.serve(buf, r) }); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeError(w, http.StatusInternalServerError, "internal", err.Error())
+		return
+	}
+	buf.copyTo(w)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Request-Id", "req-"+randomHex(8))
+	w.Header().Set("Content-Type", "application/json")
+
+	key := r.Header.Get("Idempotency-Key")
+	if r.Method != http.MethodPost || key == "" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	s.pruneIdempotentResponses()
+	if recorded, ok := s.state.IdempotentResponses[key]; ok {
+		if recorded.Method != r.Method || recorded.Path != r.URL.Path {
+			writeError(w, http.StatusUnprocessableEntity, "idempotency_key_reused",
+				fmt.Sprintf("idempotency key %q was already used for %s %s", key, recorded.Method, recorded.Path))
+			return
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		s.replay(w, recorded)
+		return
+	}
+
+	rec := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+	if rec.statusCode >= 500 {
+		return
+	}
+	recorded := &IdempotentResponse{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		StatusCode: rec.statusCode,
+		CreatedAt:  s.now(),
+	}
+	if rec.statusCode < 300 {
+		var created struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(rec.body.Bytes(), &created)
+		recorded.ObjectID = created.ID
+	} else {
+		recorded.Body = rec.body.Bytes()
+	}
+	s.state.IdempotentResponses[key] = recorded
+}
+
+// replay writes the response recorded for an idempotency key. A successful
+// create is replayed with the current version of the object it created.
+func (s *Server) replay(w http.ResponseWriter, recorded *IdempotentResponse) {
+	if recorded.ObjectID == "" {
+		w.WriteHeader(recorded.StatusCode)
+		_, _ = w.Write(recorded.Body)
+		return
+	}
+
+	v, version, ok := s.state.object(recorded.ObjectID)
+	if !ok {
+		writeErr(w, &apiError{http.StatusNotFound, "not_found",
+			fmt.Sprintf("object %s created with this idempotency key no longer exists", recorded.ObjectID)})
+		return
+	}
+	writeJSON(w, recorded.StatusCode, v, version)
+}
+
+func (s *Server) pruneIdempotentResponses() {
+	cutoff := s.now().Add(-idempotencyTTL)
+	for key, recorded := range s.state.IdempotentResponses {
+		if recorded.CreatedAt.Before(cutoff) {
+			delete(s.state.IdempotentResponses, key)
+		}
+	}
+}
+
+// recordingWriter captures the status and body written to a response.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// apiError is an error that maps to a NahCloud error response.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errInvalid(format string, args ...interface{}) error {
+	return &apiError{http.StatusBadRequest, "invalid_argument", fmt.Sprintf(format, args...)}
+}
+
+func errNotFound(kind, id string) error {
+	return &apiError{http.StatusNotFound, "not_found", fmt.Sprintf("%s %q not found", kind, id)}
+}
+
+func errConflict(format string, args ...interface{}) error {
+	return &apiError{http.StatusConflict, "conflict", fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":       code,
+			"message":    message,
+			"request_id": w.Header().Get("X-Request-Id"),
+		},
+	})
+}
+
+// writeErr writes err as an error response. Errors other than *apiError are
+// reported as internal server errors.
+func writeErr(w http.ResponseWriter, err error) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		writeError(w, apiErr.status, apiErr.code, apiErr.message)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "internal", err.Error())
+}
+
+// writeJSON writes v with the given status. A non-zero version is sent as
+// the response ETag.
+func writeJSON(w http.ResponseWriter, status int, v interface{}, version int64) {
+	if version > 0 {
+		w.Header().Set("ETag", etag(version))
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// checkIfMatch verifies the If-Match precondition of r against the current
+// version of the object.
+func checkIfMatch(r *http.Request, version int64) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return nil
+	}
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == etag(version) {
+			return nil
+		}
+	}
+	return &apiError{http.StatusPreconditionFailed, "precondition_failed", "the object was modified since it was last read"}
+}
+
+// decodeBody decodes the JSON request body into v.
+func decodeBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errInvalid("invalid request body: %s", err)
+	}
+	return nil
+}
+
+// page is a single page of a list response.
+type page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// paginate returns the page of items selected by the page_size and cursor
+// query parameters of r. Items are ordered by creation time.
+func paginate[T any](r *http.Request, items []T, createdAt func(T) time.Time, id func(T) string) (*page[T], error) {
+	slices.SortFunc(items, func(a, b T) int {
+		if c := createdAt(a).Compare(createdAt(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(id(a), id(b))
+	})
+
+	size := defaultPageSize
+	if v := r.URL.Query().Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return nil, errInvalid("page_size must be between 1 and %d", maxPageSize)
+		}
+		size = n
+	}
+
+	offset := 0
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, errInvalid("invalid cursor %q", v)
+		}
+		offset = min(n, len(items))
+	}
+
+	end := min(offset+size, len(items))
+	p := &page[T]{Items: items[offset:end]}
+	if p.Items == nil {
+		p.Items = []T{}
+	}
+	if end < len(items) {
+		p.NextCursor = strconv.Itoa(end)
+	}
+	return p, nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newID(prefix string) string {
+	return prefix + "-" + randomHex(6)
+}
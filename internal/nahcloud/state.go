@@ -0,0 +1,147 @@
+package nahcloud
+
+import (
+	"time"
+)
+
+// Project is a NahCloud project.
+type Project struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+// Instance is a NahCloud compute instance.
+type Instance struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Name      string    `json:"name"`
+	CPU       int       `json:"cpu"`
+	MemoryMB  int       `json:"memory_mb"`
+	Image     string    `json:"image"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+// Metadata is a NahCloud key-value metadata entry.
+type Metadata struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+// Bucket is a NahCloud storage bucket.
+type Bucket struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+// Object is a NahCloud storage object.
+type Object struct {
+	ID        string    `json:"id"`
+	BucketID  string    `json:"bucket_id"`
+	Path      string    `json:"path"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+// Token is a scoped, expiring NahCloud API token. The secret is only
+// returned when the token is created.
+type Token struct {
+	ID        string    `json:"id"`
+	Token     string    `json:"token,omitempty"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdempotentResponse records the response to a create request, replayed to
+// later requests with the same Idempotency-Key. A successful create only
+// records the ID of the created object, whose current version is replayed,
+// so that metadata values and object content are not kept twice.
+type IdempotentResponse struct {
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	ObjectID   string    `json:"object_id,omitempty"`
+	Body       []byte    `json:"body,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// State is the complete data set served by a Server.
+type State struct {
+	Projects            map[string]*Project            `json:"projects"`
+	Instances           map[string]*Instance           `json:"instances"`
+	Metadata            map[string]*Metadata           `json:"metadata"`
+	Buckets             map[string]*Bucket             `json:"buckets"`
+	Objects             map[string]*Object             `json:"objects"`
+	Tokens              map[string]*Token              `json:"tokens"`
+	IdempotentResponses map[string]*IdempotentResponse `json:"idempotent_responses"`
+}
+
+// NewState returns an empty State.
+func NewState() *State {
+	s := &State{}
+	s.init()
+	return s
+}
+
+// init allocates any nil maps, e.g. after decoding a partial state.
+func (s *State) init() {
+	if s.Projects == nil {
+		s.Projects = make(map[string]*Project)
+	}
+	if s.Instances == nil {
+		s.Instances = make(map[string]*Instance)
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]*Metadata)
+	}
+	if s.Buckets == nil {
+		s.Buckets = make(map[string]*Bucket)
+	}
+	if s.Objects == nil {
+		s.Objects = make(map[string]*Object)
+	}
+	if s.Tokens == nil {
+		s.Tokens = make(map[string]*Token)
+	}
+	if s.IdempotentResponses == nil {
+		s.IdempotentResponses = make(map[string]*IdempotentResponse)
+	}
+}
+
+// object returns the object of any type with the given ID, and its version.
+func (s *State) object(id string) (interface{}, int64, bool) {
+	if project, ok := s.Projects[id]; ok {
+		return project, project.Version, true
+	}
+	if instance, ok := s.Instances[id]; ok {
+		return instance, instance.Version, true
+	}
+	if metadata, ok := s.Metadata[id]; ok {
+		return metadata, metadata.Version, true
+	}
+	if bucket, ok := s.Buckets[id]; ok {
+		return bucket, bucket.Version, true
+	}
+	if object, ok := s.Objects[id]; ok {
+		return object, object.Version, true
+	}
+	if token, ok := s.Tokens[id]; ok {
+		return token, 0, true
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,107 @@
+package nahcloud
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+var (
+	sharedMu      sync.Mutex
+	sharedServers = map[string]*Server{}
+)
+
+// Shared returns the process-wide in-memory server registered under name,
+// creating it on first use. Clients configured with the same memory://name
+// endpoint see the same data.
+func Shared(name string) *Server {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	s, ok := sharedServers[name]
+	if !ok {
+		s = NewServer()
+		sharedServers[name] = s
+	}
+	return s
+}
+
+// Transport returns an http.RoundTripper that serves every request with h
+// in-process, without opening any network connection.
+func Transport(h http.Handler) http.RoundTripper {
+	return handlerTransport{handler: h}
+}
+
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	buf := newResponseBuffer()
+	t.handler.ServeHTTP(buf, req)
+	return buf.response(req), nil
+}
+
+// responseBuffer is an http.ResponseWriter that holds the response in
+// memory.
+type responseBuffer struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(statusCode int) {
+	if b.wroteHeader {
+		return
+	}
+	b.statusCode = statusCode
+	b.wroteHeader = true
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// copyTo writes the buffered response to w.
+func (b *responseBuffer) copyTo(w http.ResponseWriter) {
+	maps.Copy(w.Header(), b.header)
+	w.WriteHeader(b.statusCode)
+	_, _ = w.Write(b.body.Bytes())
+}
+
+// response returns the buffered response to req.
+func (b *responseBuffer) response(req *http.Request) *http.Response {
+	header := b.header.Clone()
+	header.Set("Content-Length", strconv.Itoa(b.body.Len()))
+	return &http.Response{
+		Status:        strconv.Itoa(b.statusCode) + " " + http.StatusText(b.statusCode),
+		StatusCode:    b.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(b.body.Bytes())),
+		ContentLength: int64(b.body.Len()),
+		Request:       req,
+	}
+}
@@ -0,0 +1,377 @@
+// Package nahtest provides a fake NahCloud server for tests. It serves the
+// full /v1 API from an in-memory nahcloud backend over a real HTTP listener,
+// and lets tests inject latency and errors or change data behind the
+// client's back.
+package nahtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hypertf/terraform-provider-nah/internal/nahcloud"
+)
+
+// Server is a fake NahCloud server listening on a local address.
+type Server struct {
+	*httptest.Server
+
+	t       testing.TB
+	backend *nahcloud.Server
+
+	mu       sync.Mutex
+	hooks    []*hook
+	requests []Request
+
+	// transitionPolls is the number of reads for which instances report a
+	// transitional status after a change; see SimulateInstanceTransitions.
+	transitionPolls int
+	transitions     map[string]*transition
+}
+
+// transition is an instance status change in progress.
+type transition struct {
+	target    string
+	remaining int
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// hook changes how requests matching pattern are served. A hook with a
+// positive remaining count expires after that many requests; otherwise it
+// applies until the test ends.
+type hook struct {
+	mux       *http.ServeMux
+	remaining int
+
+	latency time.Duration
+	status  int
+	drop    bool
+}
+
+// NewServer starts a Server with an empty state. It is closed when the test
+// and all its subtests complete.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:           t,
+		backend:     nahcloud.NewServer(),
+		transitions: make(map[string]*transition),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()})
+	h := s.matchHook(r)
+	s.mu.Unlock()
+
+	if h == nil {
+		s.serveBackend(w, r)
+		return
+	}
+
+	if h.latency > 0 {
+		// The server only notices that the client went away once the body
+		// has been read.
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		timer := time.NewTimer(h.latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case h.status != 0:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(h.status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]string{
+				"code":    "injected",
+				"message": http.StatusText(h.status) + " (injected by nahtest)",
+			},
+		})
+	case h.drop:
+		// Let the backend process the request, then close the connection
+		// without sending its response.
+		s.backend.ServeHTTP(httptest.NewRecorder(), r)
+		panic(http.ErrAbortHandler)
+	default:
+		s.serveBackend(w, r)
+	}
+}
+
+// instanceRoutes matches the requests affected by instance transitions.
+var instanceRoutes = func() *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, pattern := range []string{"POST /v1/instances", "GET /v1/instances/{id}", "PATCH /v1/instances/{id}"} {
+		mux.Handle(pattern, http.NotFoundHandler())
+	}
+	return mux
+}()
+
+// serveBackend serves r from the backend, simulating instance transitions if
+// enabled.
+func (s *Server) serveBackend(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	polls := s.transitionPolls
+	s.mu.Unlock()
+
+	_, pattern := instanceRoutes.Handler(r)
+	if polls == 0 || pattern == "" {
+		s.backend.ServeHTTP(w, r)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		s.advanceTransition(strings.TrimPrefix(r.URL.Path, "/v1/instances/"))
+		s.backend.ServeHTTP(w, r)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	s.backend.ServeHTTP(rec, r)
+	body := rec.Body.Bytes()
+
+	var instance nahcloud.Instance
+	if rec.Code < 300 && json.Unmarshal(body, &instance) == nil && instance.ID != "" {
+		target := instance.Status
+		instance.Status = "starting"
+		if target == "stopped" {
+			instance.Status = "stopping"
+		}
+		s.Update(func(state *nahcloud.State) {
+			state.Instances[instance.ID].Status = instance.Status
+		})
+		s.mu.Lock()
+		s.transitions[instance.ID] = &transition{target: target, remaining: polls}
+		s.mu.Unlock()
+		body, _ = json.Marshal(instance)
+	}
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(body)
+}
+
+// advanceTransition counts a read of the instance with the given ID, and
+// completes its transition once it has been read often enough.
+func (s *Server) advanceTransition(id string) {
+	s.mu.Lock()
+	tr, ok := s.transitions[id]
+	if ok {
+		tr.remaining--
+	}
+	done := ok && tr.remaining < 0
+	if done {
+		delete(s.transitions, id)
+	}
+	s.mu.Unlock()
+
+	if done {
+		s.Update(func(state *nahcloud.State) {
+			if instance, ok := state.Instances[id]; ok {
+				instance.Status = tr.target
+			}
+		})
+	}
+}
+
+// matchHook returns the most recently added hook matching r, consuming one
+// use of it. s.mu must be held.
+func (s *Server) matchHook(r *http.Request) *hook {
+	for i, h := range slices.Backward(s.hooks) {
+		if _, pattern := h.mux.Handler(r); pattern == "" {
+			continue
+		}
+		if h.remaining > 0 {
+			h.remaining--
+			if h.remaining == 0 {
+				s.hooks = slices.Delete(s.hooks, i, i+1)
+			}
+		}
+		return h
+	}
+	return nil
+}
+
+// addHook registers h for requests matching pattern, which uses the syntax
+// of http.ServeMux, e.g. "POST /v1/instances" or "/v1/projects/{id}". The
+// pattern "/" matches every request.
+func (s *Server) addHook(pattern string, h *hook) {
+	s.t.Helper()
+
+	h.mux = http.NewServeMux()
+	h.mux.Handle(pattern, http.NotFoundHandler())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, h)
+}
+
+// InjectLatency delays every request matching pattern by d before it is
+// served.
+func (s *Server) InjectLatency(pattern string, d time.Duration) {
+	s.addHook(pattern, &hook{latency: d})
+}
+
+// InjectStatus makes the next n requests matching pattern fail with status
+// without reaching the backend. If n is zero, all matching requests fail.
+func (s *Server) InjectStatus(pattern string, status, n int) {
+	s.addHook(pattern, &hook{status: status, remaining: n})
+}
+
+// DropResponses makes the next n requests matching pattern succeed on the
+// server, but closes the connection instead of sending the response, as if
+// it was lost in transit. If n is zero, all matching responses are dropped.
+func (s *Server) DropResponses(pattern string, n int) {
+	s.addHook(pattern, &hook{drop: true, remaining: n})
+}
+
+// ClearHooks removes all injected latency and errors.
+func (s *Server) ClearHooks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = nil
+}
+
+// SimulateInstanceTransitions makes instances pass through a transitional
+// status, "starting" or "stopping", after every create or update, like a
+// real server booting or shutting down a machine. The transitional status is
+// returned by the create or update and by the next polls reads of the
+// instance. A value of zero disables transitions.
+func (s *Server) SimulateInstanceTransitions(polls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transitionPolls = polls
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Update calls fn with exclusive access to the server state, to inspect it
+// or change it out-of-band.
+func (s *Server) Update(fn func(*nahcloud.State)) {
+	s.t.Helper()
+
+	if err := s.backend.Update(fn); err != nil {
+		s.t.Fatalf("updating fake server state: %s", err)
+	}
+}
+
+// Delete removes the object with the given ID out-of-band, as if it was
+// deleted by another client. Dependent objects are left in place.
+func (s *Server) Delete(id string) {
+	s.t.Helper()
+
+	found := false
+	s.Update(func(state *nahcloud.State) {
+		found = deleteID(state.Projects, id) || deleteID(state.Instances, id) ||
+			deleteID(state.Metadata, id) || deleteID(state.Buckets, id) ||
+			deleteID(state.Objects, id)
+	})
+	if !found {
+		s.t.Fatalf("fake server has no object with ID %q", id)
+	}
+}
+
+func deleteID[T any](m map[string]*T, id string) bool {
+	if _, ok := m[id]; !ok {
+		return false
+	}
+	delete(m, id)
+	return true
+}
+
+// UpdateProject changes a project out-of-band. Its version is incremented so
+// that requests using the previous ETag fail.
+func (s *Server) UpdateProject(id string, fn func(*nahcloud.Project)) {
+	s.t.Helper()
+	s.Update(func(state *nahcloud.State) {
+		p := mustGet(s.t, state.Projects, id)
+		fn(p)
+		p.UpdatedAt, p.Version = time.Now().UTC(), p.Version+1
+	})
+}
+
+// UpdateInstance changes an instance out-of-band, e.g. to simulate drift.
+// Its version is incremented so that requests using the previous ETag fail.
+func (s *Server) UpdateInstance(id string, fn func(*nahcloud.Instance)) {
+	s.t.Helper()
+	s.Update(func(state *nahcloud.State) {
+		i := mustGet(s.t, state.Instances, id)
+		fn(i)
+		i.UpdatedAt, i.Version = time.Now().UTC(), i.Version+1
+	})
+}
+
+// UpdateMetadata changes a metadata entry out-of-band. Its version is
+// incremented so that requests using the previous ETag fail.
+func (s *Server) UpdateMetadata(id string, fn func(*nahcloud.Metadata)) {
+	s.t.Helper()
+	s.Update(func(state *nahcloud.State) {
+		m := mustGet(s.t, state.Metadata, id)
+		fn(m)
+		m.UpdatedAt, m.Version = time.Now().UTC(), m.Version+1
+	})
+}
+
+// UpdateBucket changes a bucket out-of-band. Its version is incremented so
+// that requests using the previous ETag fail.
+func (s *Server) UpdateBucket(id string, fn func(*nahcloud.Bucket)) {
+	s.t.Helper()
+	s.Update(func(state *nahcloud.State) {
+		b := mustGet(s.t, state.Buckets, id)
+		fn(b)
+		b.UpdatedAt, b.Version = time.Now().UTC(), b.Version+1
+	})
+}
+
+// UpdateObject changes an object out-of-band. Its version is incremented so
+// that requests using the previous ETag fail.
+func (s *Server) UpdateObject(id string, fn func(*nahcloud.Object)) {
+	s.t.Helper()
+	s.Update(func(state *nahcloud.State) {
+		o := mustGet(s.t, state.Objects, id)
+		fn(o)
+		o.UpdatedAt, o.Version = time.Now().UTC(), o.Version+1
+	})
+}
+
+func mustGet[T any](t testing.TB, m map[string]*T, id string) *T {
+	t.Helper()
+
+	v, ok := m[id]
+	if !ok {
+		t.Fatalf("fake server has no object with ID %q", id)
+	}
+	return v
+}
@@ -0,0 +1,44 @@
+package nahtest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInjectStatus(t *testing.T) {
+	srv := NewServer(t)
+	srv.InjectStatus("GET /v1/projects/{id}", http.StatusTooManyRequests, 1)
+
+	get := func(path string) int {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	post := func(path string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(`{"name":"p"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := post("/v1/projects"); got != http.StatusCreated {
+		t.Errorf("expected other methods and paths to be unaffected, got %d", got)
+	}
+	if got := get("/v1/projects/proj-1"); got != http.StatusTooManyRequests {
+		t.Errorf("expected injected status, got %d", got)
+	}
+	if got := get("/v1/projects/proj-1"); got != http.StatusNotFound {
+		t.Errorf("expected the hook to expire after one use, got %d", got)
+	}
+	if got := len(srv.Requests()); got != 3 {
+		t.Errorf("expected 3 recorded requests, got %d", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
@@ -83,7 +84,7 @@ func (d *BucketDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 
 	bucket, err := d.client.GetBucket(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read bucket: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read bucket", err)
 		return
 	}
 
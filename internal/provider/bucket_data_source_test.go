@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketDataSource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBucketDataSourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nah_bucket.test", "id", "nah_bucket.test", "id"),
+					resource.TestCheckResourceAttr("data.nah_bucket.test", "name", name),
+					resource.TestCheckResourceAttrSet("data.nah_bucket.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.nah_bucket.test", "updated_at"),
+				),
+			},
+		},
+	})
+}
+
+func testAccBucketDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_bucket" "test" {
+  name = %[1]q
+}
+
+data "nah_bucket" "test" {
+  id = nah_bucket.test.id
+}
+`, name)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+func TestAccBucketResource(t *testing.T) {
+	name := testAccName()
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckBucketDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccBucketResourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_bucket.test", "name", name),
+					testAccCheckResourceID("nah_bucket.test", &id),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "nah_bucket.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccBucketResourceConfig(name + "-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_bucket.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_bucket.test", "name", name+"-renamed"),
+					resource.TestCheckResourceAttrPtr("nah_bucket.test", "id", &id),
+				),
+			},
+			// Drift is corrected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					_, err := c.UpdateBucket(ctx, id, name+"-drifted")
+					return err
+				}),
+				Config: testAccBucketResourceConfig(name + "-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_bucket.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("nah_bucket.test", "name", name+"-renamed"),
+			},
+			// Deletion outside of Terraform is detected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					return c.DeleteBucket(ctx, id)
+				}),
+				Config: testAccBucketResourceConfig(name + "-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_bucket.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckBucketDestroy(s *terraform.State) error {
+	return testAccCheckDestroy("nah_bucket", func(ctx context.Context, c *client.Client, rs *terraform.ResourceState) error {
+		_, err := c.GetBucket(ctx, rs.Primary.ID)
+		return err
+	})(s)
+}
+
+func testAccBucketResourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_bucket" "test" {
+  name = %[1]q
+}
+`, name)
+}
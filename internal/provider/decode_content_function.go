@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &DecodeContentFunction{}
+
+func NewDecodeContentFunction() function.Function {
+	return &DecodeContentFunction{}
+}
+
+// DecodeContentFunction decodes object content into a string.
+type DecodeContentFunction struct{}
+
+func (f *DecodeContentFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "decode_content"
+}
+
+func (f *DecodeContentFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Decode object content into a string",
+		MarkdownDescription: "Decodes the base64 `content` of a `nah_object` into a string. The decoded content must be valid UTF-8.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "content",
+				MarkdownDescription: "The base64-encoded content.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *DecodeContentFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &content))
+	if resp.Error != nil {
+		return
+	}
+
+	text, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("content is not valid base64: %s", err))
+		return
+	}
+	if !utf8.Valid(text) {
+		resp.Error = function.NewArgumentFuncError(0, "the decoded content is not valid UTF-8")
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, string(text)))
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestDecodeContentFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_8_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+  value = provider::nah::decode_content(provider::nah::encode_content("Hello, World!"))
+}
+`,
+				Check: resource.TestCheckOutput("test", "Hello, World!"),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::nah::decode_content("not base64!")
+}
+`,
+				ExpectError: regexp.MustCompile(`content is not valid base64`),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::nah::decode_content("/w==")
+}
+`,
+				ExpectError: regexp.MustCompile(`not valid UTF-8`),
+			},
+		},
+	})
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+// addClientError appends a diagnostic for an error returned by the NahCloud
+// client while performing action, e.g. "create instance". Objects that were
+// not found and unexpected states are attributed to attrPath, conflicts to
+// conflictPath; an empty path attaches the error to the resource. NahCloud
+// does not say which field failed validation, so invalid requests are always
+// attached to the resource.
+func addClientError(diags *diag.Diagnostics, attrPath, conflictPath path.Path, action string, err error) {
+	var stateErr *client.UnexpectedStateError
+	if errors.As(err, &stateErr) {
+		diags.AddAttributeError(
+			attrPath,
+			fmt.Sprintf("Unable to %s: unexpected state", action),
+			fmt.Sprintf("Could not %s: NahCloud reported the state %q, which is not one of the expected states (%s).\n\n"+
+				"The object may have failed, or been changed outside of Terraform. Inspect it in NahCloud, then run terraform apply again.",
+				action, stateErr.State, strings.Join(slices.Concat(stateErr.Pending, stateErr.Target), ", ")),
+		)
+		return
+	}
+
+	var timeoutErr *client.WaitTimeoutError
+	if errors.As(err, &timeoutErr) {
+		diags.AddError(
+			fmt.Sprintf("Unable to %s: timed out", action),
+			fmt.Sprintf("Could not %s: %s.\n\n"+
+				"The object is still changing. Wait for it to settle, or increase the timeouts of the resource, then run terraform apply again.", action, timeoutErr),
+		)
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		diags.AddError(
+			fmt.Sprintf("Unable to %s: timed out", action),
+			fmt.Sprintf("Could not %s before the operation timed out: %s\n\n"+
+				"NahCloud may be slow or unreachable. Retry later, or increase the timeouts of the resource.", action, err),
+		)
+		return
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError(
+			fmt.Sprintf("Unable to %s", action),
+			fmt.Sprintf("Could not %s: %s\n\n"+
+				"Check that the provider endpoint (or NAH_ENDPOINT) points to a reachable NahCloud server.", action, err),
+		)
+		return
+	}
+
+	var kind, hint string
+	var attributeErrorPath path.Path
+
+	switch {
+	case client.IsNotFound(err):
+		kind = "not found"
+		hint = "The referenced object does not exist. Verify the ID is correct and that the object was not deleted outside of Terraform."
+		attributeErrorPath = attrPath
+	case client.IsConflict(err):
+		kind = "conflict"
+		hint = "The request conflicts with an existing object. Choose a different value, or import the existing object with \"terraform import\"."
+		attributeErrorPath = conflictPath
+	case client.IsPreconditionFailed(err):
+		kind = "modified concurrently"
+		hint = "The object was modified outside of this Terraform run after it was last read. Run terraform plan again to review the current state before re-applying."
+	case client.IsBadRequest(err):
+		kind = "invalid request"
+		hint = "NahCloud rejected the request as invalid. Review the configured values and try again."
+	case client.IsUnauthorized(err):
+		kind = "unauthorized"
+		hint = "Check that the provider token (or NAH_TOKEN) is set and valid."
+	case client.IsForbidden(err):
+		kind = "permission denied"
+		hint = "The configured token is not allowed to perform this operation. Use a token with the required permissions."
+	case client.IsRateLimited(err):
+		kind = "rate limited"
+		hint = "NahCloud is throttling requests. Retry later or reduce Terraform's -parallelism."
+	case client.IsServerError(err):
+		kind = "server error"
+		hint = "NahCloud failed to process the request. This is usually transient; retrying the operation may succeed."
+	default:
+		kind = "unexpected response"
+		hint = "NahCloud returned an unexpected response. Please report this issue to the provider developers."
+	}
+
+	summary := fmt.Sprintf("Unable to %s: %s", action, kind)
+
+	var detail strings.Builder
+	fmt.Fprintf(&detail, "Could not %s: %s\n\n%s\n\nHTTP status: %d", action, apiErr.Message, hint, apiErr.StatusCode)
+	if apiErr.Code != "" {
+		fmt.Fprintf(&detail, "\nError code: %s", apiErr.Code)
+	}
+	if apiErr.RequestID != "" {
+		fmt.Fprintf(&detail, "\nRequest ID: %s", apiErr.RequestID)
+	}
+
+	if len(attributeErrorPath.Steps()) > 0 {
+		diags.AddAttributeError(attributeErrorPath, summary, detail.String())
+		return
+	}
+	diags.AddError(summary, detail.String())
+}
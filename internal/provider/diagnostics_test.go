@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+func TestAddClientErrorPath(t *testing.T) {
+	attrPath, conflictPath := path.Root("bucket_id"), path.Root("path")
+	apiError := func(status int) error {
+		return fmt.Errorf("creating object: %w", &client.APIError{StatusCode: status, Message: http.StatusText(status)})
+	}
+
+	tests := []struct {
+		name         string
+		attrPath     path.Path
+		conflictPath path.Path
+		err          error
+		want         path.Path
+	}{
+		{"not found", attrPath, conflictPath, apiError(http.StatusNotFound), attrPath},
+		{"conflict", attrPath, conflictPath, apiError(http.StatusConflict), conflictPath},
+		{"bad request", attrPath, conflictPath, apiError(http.StatusBadRequest), path.Empty()},
+		{"unprocessable", attrPath, conflictPath, apiError(http.StatusUnprocessableEntity), path.Empty()},
+		{"server error", attrPath, conflictPath, apiError(http.StatusInternalServerError), path.Empty()},
+		{"not found without path", path.Empty(), conflictPath, apiError(http.StatusNotFound), path.Empty()},
+		{"conflict without path", attrPath, path.Empty(), apiError(http.StatusConflict), path.Empty()},
+		{"unexpected state", path.Root("status"), path.Empty(), &client.UnexpectedStateError{State: "error"}, path.Root("status")},
+	}
+	for _, tt := range tests {
+		var diags diag.Diagnostics
+		addClientError(&diags, tt.attrPath, tt.conflictPath, "create object", tt.err)
+		if len(diags) != 1 {
+			t.Fatalf("%s: expected 1 diagnostic, got %d", tt.name, len(diags))
+		}
+
+		got := path.Empty()
+		if d, ok := diags[0].(diag.DiagnosticWithPath); ok {
+			got = d.Path()
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: attached to %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
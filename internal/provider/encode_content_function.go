@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &EncodeContentFunction{}
+
+func NewEncodeContentFunction() function.Function {
+	return &EncodeContentFunction{}
+}
+
+// EncodeContentFunction encodes a string as object content.
+type EncodeContentFunction struct{}
+
+func (f *EncodeContentFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "encode_content"
+}
+
+func (f *EncodeContentFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Encode a string as object content",
+		MarkdownDescription: "Encodes a string with standard, padded base64, as expected by `nah_object.content`. Use `decode_content` to decode it again.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "text",
+				MarkdownDescription: "The text to encode.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *EncodeContentFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var text string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &text))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, base64.StdEncoding.EncodeToString([]byte(text))))
+}
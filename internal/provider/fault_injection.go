@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+// faultEnvPrefix is the prefix of the environment variables that configure
+// fault injection.
+const faultEnvPrefix = "NAH_FAULT_"
+
+// NahProviderFaultInjectionModel describes the fault_injection block of the
+// provider.
+type NahProviderFaultInjectionModel struct {
+	Seed             types.Int64   `tfsdk:"seed"`
+	Operations       types.List    `tfsdk:"operations"`
+	Latency          types.String  `tfsdk:"latency"`
+	ErrorRate        types.Float64 `tfsdk:"error_rate"`
+	ErrorStatusCodes types.List    `tfsdk:"error_status_codes"`
+	TimeoutRate      types.Float64 `tfsdk:"timeout_rate"`
+	Timeout          types.String  `tfsdk:"timeout"`
+	ResponseLostRate types.Float64 `tfsdk:"response_lost_rate"`
+	MaxFaults        types.Int64   `tfsdk:"max_faults"`
+}
+
+// faultInjectionEnvSet reports whether any NAH_FAULT_* environment variable
+// is set.
+func faultInjectionEnvSet() bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, faultEnvPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// faultInjection builds the fault injection configuration for the client from
+// the fault_injection block and NAH_FAULT_* environment variables. It returns
+// nil when neither is set.
+func (m NahProviderModel) faultInjection(ctx context.Context, diags *diag.Diagnostics) *client.FaultInjection {
+	if m.FaultInjection == nil && !faultInjectionEnvSet() {
+		return nil
+	}
+
+	data := m.FaultInjection
+	if data == nil {
+		data = &NahProviderFaultInjectionModel{
+			Seed:             types.Int64Null(),
+			Operations:       types.ListNull(types.StringType),
+			Latency:          types.StringNull(),
+			ErrorRate:        types.Float64Null(),
+			ErrorStatusCodes: types.ListNull(types.Int64Type),
+			TimeoutRate:      types.Float64Null(),
+			Timeout:          types.StringNull(),
+			ResponseLostRate: types.Float64Null(),
+			MaxFaults:        types.Int64Null(),
+		}
+	}
+	block := path.Root("fault_injection")
+
+	f := &client.FaultInjection{
+		ErrorRate:        float64WithEnvFallback(data.ErrorRate, faultEnvPrefix+"ERROR_RATE", block.AtName("error_rate"), diags),
+		TimeoutRate:      float64WithEnvFallback(data.TimeoutRate, faultEnvPrefix+"TIMEOUT_RATE", block.AtName("timeout_rate"), diags),
+		ResponseLostRate: float64WithEnvFallback(data.ResponseLostRate, faultEnvPrefix+"RESPONSE_LOST_RATE", block.AtName("response_lost_rate"), diags),
+		MaxFaults:        int(int64WithEnvFallback(data.MaxFaults, faultEnvPrefix+"MAX_FAULTS", block.AtName("max_faults"), diags)),
+		Latency:          durationWithEnvFallback(data.Latency, faultEnvPrefix+"LATENCY", block.AtName("latency"), diags),
+		Timeout:          durationWithEnvFallback(data.Timeout, faultEnvPrefix+"TIMEOUT", block.AtName("timeout"), diags),
+	}
+
+	if !data.Operations.IsNull() {
+		diags.Append(data.Operations.ElementsAs(ctx, &f.Operations, false)...)
+	} else if v := os.Getenv(faultEnvPrefix + "OPERATIONS"); v != "" {
+		for _, op := range strings.Split(v, ",") {
+			f.Operations = append(f.Operations, strings.TrimSpace(op))
+		}
+	}
+
+	if !data.ErrorStatusCodes.IsNull() {
+		var codes []int64
+		diags.Append(data.ErrorStatusCodes.ElementsAs(ctx, &codes, false)...)
+		for _, code := range codes {
+			f.ErrorStatusCodes = append(f.ErrorStatusCodes, int(code))
+		}
+	} else if v := os.Getenv(faultEnvPrefix + "ERROR_STATUS_CODES"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				diags.AddAttributeError(
+					block.AtName("error_status_codes"),
+					"Invalid "+faultEnvPrefix+"ERROR_STATUS_CODES",
+					fmt.Sprintf("%q is not a comma-separated list of status codes.", v),
+				)
+				break
+			}
+			f.ErrorStatusCodes = append(f.ErrorStatusCodes, code)
+		}
+	}
+
+	// Without an explicit seed every run injects different faults. The seed
+	// is reported so that an interesting run can be reproduced.
+	if !data.Seed.IsNull() || os.Getenv(faultEnvPrefix+"SEED") != "" {
+		f.Seed = uint64(int64WithEnvFallback(data.Seed, faultEnvPrefix+"SEED", block.AtName("seed"), diags))
+	} else {
+		f.Seed = rand.Uint64() >> 1
+	}
+
+	if err := f.Validate(); err != nil {
+		diags.AddAttributeError(block, "Invalid Fault Injection", err.Error())
+		return nil
+	}
+
+	tflog.Info(ctx, "Fault injection enabled", map[string]interface{}{
+		"seed":               int64(f.Seed),
+		"operations":         f.Operations,
+		"latency":            f.Latency.String(),
+		"error_rate":         f.ErrorRate,
+		"timeout_rate":       f.TimeoutRate,
+		"response_lost_rate": f.ResponseLostRate,
+		"max_faults":         f.MaxFaults,
+	})
+	diags.AddAttributeWarning(
+		block,
+		"Fault Injection Enabled",
+		fmt.Sprintf("The provider is injecting faults into NahCloud API requests, so operations may fail or be delayed on purpose. "+
+			"Set seed = %d (or %sSEED=%[1]d) to reproduce this run.", int64(f.Seed), faultEnvPrefix),
+	)
+	return f
+}
+
+// durationWithEnvFallback returns the duration in value, or in the
+// environment variable env when value is null. It returns zero if neither is
+// set.
+func durationWithEnvFallback(value types.String, env string, attrPath path.Path, diags *diag.Diagnostics) time.Duration {
+	if value.IsNull() {
+		value = types.StringValue(os.Getenv(env))
+	}
+	if value.ValueString() == "" {
+		return 0
+	}
+	return parseDuration(attrPath, value, diags)
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProvider_faultInjection(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Invalid rates are rejected
+			{
+				Config: `
+provider "nah" {
+  fault_injection {
+    error_rate   = 0.8
+    timeout_rate = 0.8
+  }
+}
+` + testAccProjectResourceConfig(name),
+				ExpectError: regexp.MustCompile(`must not exceed 1`),
+			},
+			// Injected failures are retried
+			{
+				Config: fmt.Sprintf(`
+provider "nah" {
+  retry {
+    base_delay = "1ms"
+  }
+
+  fault_injection {
+    seed               = 1
+    operations         = ["CreateProject"]
+    error_rate         = 0.5
+    response_lost_rate = 0.5
+    max_faults         = 2
+  }
+}
+%s`, testAccProjectResourceConfig(name)),
+				Check: resource.TestCheckResourceAttr("nah_project.test", "name", name),
+			},
+			// Injected failures surface once retries are exhausted
+			{
+				Config: fmt.Sprintf(`
+provider "nah" {
+  retry {
+    max_attempts = 1
+  }
+
+  fault_injection {
+    operations         = ["UpdateProject"]
+    error_rate         = 1
+    error_status_codes = [503]
+  }
+}
+%s`, testAccProjectResourceConfig(name+"-renamed")),
+				ExpectError: regexp.MustCompile(`Unable to update project: server error`),
+			},
+		},
+	})
+}
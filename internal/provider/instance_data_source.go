@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
@@ -108,7 +109,7 @@ func (d *InstanceDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	instance, err := d.client.GetInstance(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read instance: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read instance", err)
 		return
 	}
 
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccInstanceDataSource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceDataSourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nah_instance.test", "id", "nah_instance.test", "id"),
+					resource.TestCheckResourceAttrPair("data.nah_instance.test", "project_id", "nah_project.test", "id"),
+					resource.TestCheckResourceAttr("data.nah_instance.test", "name", name),
+					resource.TestCheckResourceAttr("data.nah_instance.test", "cpu", "2"),
+					resource.TestCheckResourceAttr("data.nah_instance.test", "memory_mb", "1024"),
+					resource.TestCheckResourceAttr("data.nah_instance.test", "image", "ubuntu:22.04"),
+					resource.TestCheckResourceAttr("data.nah_instance.test", "status", "running"),
+					resource.TestCheckResourceAttrSet("data.nah_instance.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.nah_instance.test", "updated_at"),
+				),
+			},
+		},
+	})
+}
+
+func testAccInstanceDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_project" "test" {
+  name = %[1]q
+}
+
+resource "nah_instance" "test" {
+  project_id = nah_project.test.id
+  name       = %[1]q
+  cpu        = 2
+  memory_mb  = 1024
+  image      = "ubuntu:22.04"
+}
+
+data "nah_instance" "test" {
+  id = nah_instance.test.id
+}
+`, name)
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/action/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+var _ action.Action = &InstancePowerAction{}
+var _ action.ActionWithConfigure = &InstancePowerAction{}
+
+func NewInstanceStartAction() action.Action {
+	return &InstancePowerAction{
+		name:        "instance_start",
+		description: "Starts a NahCloud instance and waits until it is running. Does nothing if the instance is already running.",
+		statuses:    []string{client.InstanceRunning},
+	}
+}
+
+func NewInstanceStopAction() action.Action {
+	return &InstancePowerAction{
+		name:        "instance_stop",
+		description: "Stops a NahCloud instance and waits until it is stopped. Does nothing if the instance is already stopped.",
+		statuses:    []string{client.InstanceStopped},
+	}
+}
+
+func NewInstanceRestartAction() action.Action {
+	return &InstancePowerAction{
+		name:        "instance_restart",
+		description: "Stops a NahCloud instance, then starts it again and waits until it is running. A stopped instance is only started.",
+		statuses:    []string{client.InstanceStopped, client.InstanceRunning},
+	}
+}
+
+// InstancePowerAction changes the status of an instance outside of its
+// nah_instance resource, taking it through statuses in order.
+type InstancePowerAction struct {
+	client *client.Client
+
+	name        string
+	description string
+	statuses    []string
+}
+
+type InstancePowerActionModel struct {
+	InstanceID types.String   `tfsdk:"instance_id"`
+	Timeouts   timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (a *InstancePowerAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + a.name
+}
+
+func (a *InstancePowerAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: a.description + " The `status` of a `nah_instance` resource managing the instance is not changed, " +
+			"so the next apply restores it unless the configuration is updated too.",
+
+		Attributes: map[string]schema.Attribute{
+			"instance_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The ID of the instance.",
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (a *InstancePowerAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	a.client = c
+}
+
+func (a *InstancePowerAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data InstancePowerActionModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	invokeTimeout, diags := data.Timeouts.Invoke(ctx, defaultInvokeTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, invokeTimeout)
+	defer cancel()
+
+	instance, err := a.client.GetInstance(ctx, data.InstanceID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, path.Root("instance_id"), path.Empty(), "read instance", err)
+		return
+	}
+
+	for _, status := range a.statuses {
+		if instance.Status == status {
+			resp.SendProgress(action.InvokeProgressEvent{
+				Message: fmt.Sprintf("Instance %s is already %s", instance.ID, status),
+			})
+			continue
+		}
+
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("Changing the status of instance %s from %s to %s", instance.ID, instance.Status, status),
+		})
+		from := instance.Status
+		updateReq := &client.UpdateInstanceRequest{Status: &status}
+		instance, err = a.client.UpdateInstance(ctx, instance.ID, updateReq, client.IfMatch(instance.ETag))
+		if err != nil {
+			addClientError(&resp.Diagnostics, path.Root("instance_id"), path.Empty(), "update instance status", err)
+			return
+		}
+
+		instance, err = a.client.WaitForInstanceStatus(ctx, instance.ID, from, status)
+		if err != nil {
+			addClientError(&resp.Diagnostics, path.Root("instance_id"), path.Empty(), fmt.Sprintf("wait for instance to become %s", status), err)
+			return
+		}
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("Instance %s is %s", instance.ID, status),
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestAccInstancePowerAction(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to count status changes")
+	}
+	name := testAccName()
+	var srv *nahtest.Server
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			srv = nahtest.NewServer(t)
+			srv.SimulateInstanceTransitions(1)
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_14_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			// Restarting stops and starts the instance, which ends up running
+			// as configured
+			{
+				Config: testAccInstancePowerActionConfig(name, "nah_instance_restart"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckInstanceStatus("nah_instance.test", "running"),
+					func(s *terraform.State) error {
+						if n := testAccCountInstanceUpdates(srv); n != 2 {
+							return fmt.Errorf("expected 2 status changes, got %d", n)
+						}
+						return nil
+					},
+				),
+			},
+			// Stopping leaves the instance stopped, which the next plan
+			// reverts
+			{
+				Config:             testAccInstancePowerActionConfig(name, "nah_instance_stop"),
+				Check:              testAccCheckInstanceStatus("nah_instance.test", "stopped"),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testAccCheckInstanceStatus checks the status of the instance at addr in
+// NahCloud, which actions change without updating the state.
+func testAccCheckInstanceStatus(addr, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		var id string
+		if err := testAccCheckResourceID(addr, &id)(s); err != nil {
+			return err
+		}
+		instance, err := testAccClient().GetInstance(context.Background(), id)
+		if err != nil {
+			return err
+		}
+		if instance.Status != want {
+			return fmt.Errorf("expected status %q, got %q", want, instance.Status)
+		}
+		return nil
+	}
+}
+
+// testAccCountInstanceUpdates returns the number of instance updates srv has
+// received.
+func testAccCountInstanceUpdates(srv *nahtest.Server) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Method == http.MethodPatch {
+			n++
+		}
+	}
+	return n
+}
+
+// testAccInstancePowerActionConfig returns a configuration that invokes the
+// action on a running instance once it is created, and again whenever the
+// action type changes.
+func testAccInstancePowerActionConfig(name, actionType string) string {
+	return fmt.Sprintf(`
+resource "nah_project" "test" {
+  name = %[1]q
+}
+
+resource "nah_instance" "test" {
+  project_id = nah_project.test.id
+  name       = %[1]q
+  image      = "ubuntu:22.04"
+}
+
+action %[2]q "test" {
+  config {
+    instance_id = nah_instance.test.id
+  }
+}
+
+resource "terraform_data" "trigger" {
+  input = "${nah_instance.test.id}/%[2]s"
+
+  lifecycle {
+    action_trigger {
+      events  = [after_create, after_update]
+      actions = [action.%[2]s.test]
+    }
+  }
+}
+`, name, actionType)
+}
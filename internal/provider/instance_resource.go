@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -12,6 +14,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
 
@@ -27,13 +30,14 @@ type InstanceResource struct {
 }
 
 type InstanceResourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	ProjectID types.String `tfsdk:"project_id"`
-	Name      types.String `tfsdk:"name"`
-	CPU       types.Int64  `tfsdk:"cpu"`
-	MemoryMB  types.Int64  `tfsdk:"memory_mb"`
-	Image     types.String `tfsdk:"image"`
-	Status    types.String `tfsdk:"status"`
+	ID        types.String   `tfsdk:"id"`
+	ProjectID types.String   `tfsdk:"project_id"`
+	Name      types.String   `tfsdk:"name"`
+	CPU       types.Int64    `tfsdk:"cpu"`
+	MemoryMB  types.Int64    `tfsdk:"memory_mb"`
+	Image     types.String   `tfsdk:"image"`
+	Status    types.String   `tfsdk:"status"`
+	Timeouts  timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *InstanceResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -83,9 +87,18 @@ func (r *InstanceResource) Schema(ctx context.Context, req resource.SchemaReques
 				Optional:            true,
 				Computed:            true,
 				Default:             stringdefault.StaticString("running"),
-				MarkdownDescription: "The status of the instance. Valid values: `running`, `stopped`. Defaults to `running`.",
+				MarkdownDescription: "The status of the instance. Valid values: `running`, `stopped`. Defaults to `running`. Create and update wait until the instance reports this status, past transitional states such as `starting`.",
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -114,6 +127,15 @@ func (r *InstanceResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	createReq := &client.CreateInstanceRequest{
 		ProjectID: data.ProjectID.ValueString(),
 		Name:      data.Name.ValueString(),
@@ -125,9 +147,12 @@ func (r *InstanceResource) Create(ctx context.Context, req resource.CreateReques
 
 	instance, err := r.client.CreateInstance(ctx, createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create instance: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("project_id"), path.Empty(), "create instance", err)
 		return
 	}
+	// The instance is saved to state even if it fails to reach the status,
+	// so that it is replaced rather than leaked.
+	instance = r.waitForStatus(ctx, instance, "", createReq.Status, &resp.Diagnostics)
 
 	data.ID = types.StringValue(instance.ID)
 	data.ProjectID = types.StringValue(instance.ProjectID)
@@ -137,6 +162,7 @@ func (r *InstanceResource) Create(ctx context.Context, req resource.CreateReques
 	data.Image = types.StringValue(instance.Image)
 	data.Status = types.StringValue(instance.Status)
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, instance.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -148,9 +174,23 @@ func (r *InstanceResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	instance, err := r.client.GetInstance(ctx, data.ID.ValueString())
+	if client.IsNotFound(err) {
+		tflog.Warn(ctx, "Instance no longer exists, removing from state", map[string]interface{}{"id": data.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read instance: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read instance", err)
 		return
 	}
 
@@ -161,6 +201,7 @@ func (r *InstanceResource) Read(ctx context.Context, req resource.ReadRequest, r
 	data.Image = types.StringValue(instance.Image)
 	data.Status = types.StringValue(instance.Status)
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, instance.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -172,6 +213,15 @@ func (r *InstanceResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	name := data.Name.ValueString()
 	cpu := int(data.CPU.ValueInt64())
 	memoryMB := int(data.MemoryMB.ValueInt64())
@@ -186,11 +236,19 @@ func (r *InstanceResource) Update(ctx context.Context, req resource.UpdateReques
 		Status:   &status,
 	}
 
-	instance, err := r.client.UpdateInstance(ctx, data.ID.ValueString(), updateReq)
+	var priorStatus types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("status"), &priorStatus)...)
+	etag := getPrivateETag(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	instance, err := r.client.UpdateInstance(ctx, data.ID.ValueString(), updateReq, client.IfMatch(etag))
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update instance: %s", err))
+		addClientError(&resp.Diagnostics, path.Empty(), path.Empty(), "update instance", err)
 		return
 	}
+	instance = r.waitForStatus(ctx, instance, priorStatus.ValueString(), status, &resp.Diagnostics)
 
 	data.Name = types.StringValue(instance.Name)
 	data.CPU = types.Int64Value(int64(instance.CPU))
@@ -198,6 +256,7 @@ func (r *InstanceResource) Update(ctx context.Context, req resource.UpdateReques
 	data.Image = types.StringValue(instance.Image)
 	data.Status = types.StringValue(instance.Status)
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, instance.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -209,11 +268,49 @@ func (r *InstanceResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
-	err := r.client.DeleteInstance(ctx, data.ID.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete instance: %s", err))
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	etag := getPrivateETag(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	err := r.client.DeleteInstance(ctx, data.ID.ValueString(), client.IfMatch(etag))
+	if err != nil && !client.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "delete instance", err)
+		return
+	}
+}
+
+// waitForStatus waits until instance reports status, which it may not do
+// right after it was created or updated from status from, and returns its
+// latest version. If the wait fails, an error is added to diags and the last
+// version read is returned.
+func (r *InstanceResource) waitForStatus(ctx context.Context, instance *client.Instance, from, status string, diags *diag.Diagnostics) *client.Instance {
+	if instance.Status == status {
+		return instance
+	}
+
+	tflog.Debug(ctx, "Waiting for instance status", map[string]interface{}{
+		"id":     instance.ID,
+		"status": instance.Status,
+		"target": status,
+	})
+	latest, err := r.client.WaitForInstanceStatus(ctx, instance.ID, from, status)
+	if err != nil {
+		addClientError(diags, path.Root("status"), path.Empty(), fmt.Sprintf("wait for instance to become %s", status), err)
+	}
+	if latest == nil {
+		return instance
+	}
+	return latest
 }
 
 func (r *InstanceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestAccInstanceResource(t *testing.T) {
+	name := testAccName()
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing, with defaults
+			{
+				Config: testAccInstanceResourceConfig(name, "a", `
+  image = "ubuntu:22.04"
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_instance.test", "name", name),
+					resource.TestCheckResourceAttrPair("nah_instance.test", "project_id", "nah_project.a", "id"),
+					resource.TestCheckResourceAttr("nah_instance.test", "cpu", "1"),
+					resource.TestCheckResourceAttr("nah_instance.test", "memory_mb", "512"),
+					resource.TestCheckResourceAttr("nah_instance.test", "image", "ubuntu:22.04"),
+					resource.TestCheckResourceAttr("nah_instance.test", "status", "running"),
+					testAccCheckResourceID("nah_instance.test", &id),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "nah_instance.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccInstanceResourceConfig(name, "a", `
+  cpu       = 4
+  memory_mb = 2048
+  image     = "ubuntu:24.04"
+  status    = "stopped"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_instance.test", "cpu", "4"),
+					resource.TestCheckResourceAttr("nah_instance.test", "memory_mb", "2048"),
+					resource.TestCheckResourceAttr("nah_instance.test", "image", "ubuntu:24.04"),
+					resource.TestCheckResourceAttr("nah_instance.test", "status", "stopped"),
+					resource.TestCheckResourceAttrPtr("nah_instance.test", "id", &id),
+				),
+			},
+			// Drift is corrected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					cpu, status := 8, "running"
+					_, err := c.UpdateInstance(ctx, id, &client.UpdateInstanceRequest{CPU: &cpu, Status: &status})
+					return err
+				}),
+				Config: testAccInstanceResourceConfig(name, "a", `
+  cpu       = 4
+  memory_mb = 2048
+  image     = "ubuntu:24.04"
+  status    = "stopped"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_instance.test", "cpu", "4"),
+					resource.TestCheckResourceAttr("nah_instance.test", "status", "stopped"),
+				),
+			},
+			// Changing project_id replaces the instance
+			{
+				Config: testAccInstanceResourceConfig(name, "b", `
+  cpu       = 4
+  memory_mb = 2048
+  image     = "ubuntu:24.04"
+  status    = "stopped"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_instance.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("nah_instance.test", "project_id", "nah_project.b", "id"),
+					testAccCheckResourceID("nah_instance.test", &id),
+				),
+			},
+			// Deletion outside of Terraform is detected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					return c.DeleteInstance(ctx, id)
+				}),
+				Config: testAccInstanceResourceConfig(name, "b", `
+  cpu       = 4
+  memory_mb = 2048
+  image     = "ubuntu:24.04"
+  status    = "stopped"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_instance.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_waitForStatus(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to simulate status transitions")
+	}
+	name := testAccName()
+	var srv *nahtest.Server
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			srv = nahtest.NewServer(t)
+			srv.SimulateInstanceTransitions(1)
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			// The instance is created "starting" and becomes "running"
+			{
+				Config: testAccInstanceResourceConfig(name, "a", `
+  image = "ubuntu:22.04"
+`),
+				Check: resource.TestCheckResourceAttr("nah_instance.test", "status", "running"),
+			},
+			// Stopping goes through "stopping"
+			{
+				Config: testAccInstanceResourceConfig(name, "a", `
+  image  = "ubuntu:22.04"
+  status = "stopped"
+`),
+				Check: resource.TestCheckResourceAttr("nah_instance.test", "status", "stopped"),
+			},
+			// The wait is bounded by the update timeout
+			{
+				PreConfig: func() { srv.SimulateInstanceTransitions(1000) },
+				Config: testAccInstanceResourceConfig(name, "a", `
+  image = "ubuntu:22.04"
+
+  timeouts {
+    update = "500ms"
+  }
+`),
+				ExpectError: regexp.MustCompile(`timed out waiting for\s+"running"`),
+			},
+		},
+	})
+}
+
+func testAccCheckInstanceDestroy(s *terraform.State) error {
+	return testAccCheckDestroy("nah_instance", func(ctx context.Context, c *client.Client, rs *terraform.ResourceState) error {
+		_, err := c.GetInstance(ctx, rs.Primary.ID)
+		return err
+	})(s)
+}
+
+// testAccInstanceResourceConfig returns a configuration with two projects,
+// "a" and "b", and an instance in the given one.
+func testAccInstanceResourceConfig(name, project, attributes string) string {
+	return fmt.Sprintf(`
+resource "nah_project" "a" {
+  name = "%[1]s-a"
+}
+
+resource "nah_project" "b" {
+  name = "%[1]s-b"
+}
+
+resource "nah_instance" "test" {
+  project_id = nah_project.%[2]s.id
+  name       = %[1]q
+%[3]s}
+`, name, project, attributes)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
@@ -88,7 +89,7 @@ func (d *MetadataDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	metadata, err := d.client.GetMetadata(ctx, data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read metadata: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read metadata", err)
 		return
 	}
 
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccMetadataDataSource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataDataSourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nah_metadata.test", "id", "nah_metadata.test", "id"),
+					resource.TestCheckResourceAttr("data.nah_metadata.test", "path", "/"+name+"/config"),
+					resource.TestCheckResourceAttr("data.nah_metadata.test", "value", "hello"),
+					resource.TestCheckResourceAttrSet("data.nah_metadata.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.nah_metadata.test", "updated_at"),
+				),
+			},
+		},
+	})
+}
+
+func testAccMetadataDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path  = "/%[1]s/config"
+  value = "hello"
+}
+
+data "nah_metadata" "test" {
+  id = nah_metadata.test.id
+}
+`, name)
+}
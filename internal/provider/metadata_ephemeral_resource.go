@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+var _ ephemeral.EphemeralResource = &MetadataEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &MetadataEphemeralResource{}
+
+func NewMetadataEphemeralResource() ephemeral.EphemeralResource {
+	return &MetadataEphemeralResource{}
+}
+
+type MetadataEphemeralResource struct {
+	client *client.Client
+}
+
+type MetadataEphemeralResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Path      types.String `tfsdk:"path"`
+	Value     types.String `tfsdk:"value"`
+	CreatedAt types.String `tfsdk:"created_at"`
+	UpdatedAt types.String `tfsdk:"updated_at"`
+}
+
+func (r *MetadataEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata"
+}
+
+func (r *MetadataEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads a NahCloud metadata entry without storing its value in the plan or state. " +
+			"Use it instead of the `nah_metadata` data source for secrets.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The unique identifier of the metadata entry.",
+			},
+			"path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The path for the metadata entry.",
+			},
+			"value": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The value for the metadata entry.",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The timestamp when the metadata was created.",
+			},
+			"updated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The timestamp when the metadata was last updated.",
+			},
+		},
+	}
+}
+
+func (r *MetadataEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = c
+}
+
+func (r *MetadataEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data MetadataEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	metadata, err := r.client.GetMetadata(ctx, data.ID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read metadata", err)
+		return
+	}
+
+	data.Path = types.StringValue(metadata.Path)
+	data.Value = types.StringValue(metadata.Value)
+	data.CreatedAt = types.StringValue(metadata.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	data.UpdatedAt = types.StringValue(metadata.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
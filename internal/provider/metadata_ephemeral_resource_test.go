@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccMetadataEphemeralResource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_10_0),
+		ProtoV6ProviderFactories: testAccEchoProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataEphemeralResourceConfig(name),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("path"), knownvalue.StringExact("/"+name+"/secret")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("value"), knownvalue.StringExact("hunter2")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("created_at"), knownvalue.NotNull()),
+				},
+			},
+		},
+	})
+}
+
+func testAccMetadataEphemeralResourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path  = "/%[1]s/secret"
+  value = "hunter2"
+}
+
+ephemeral "nah_metadata" "test" {
+  id = nah_metadata.test.id
+}
+
+provider "echo" {
+  data = ephemeral.nah_metadata.test
+}
+
+resource "echo" "test" {}
+`, name)
+}
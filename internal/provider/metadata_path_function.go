@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &MetadataPathFunction{}
+
+func NewMetadataPathFunction() function.Function {
+	return &MetadataPathFunction{}
+}
+
+// MetadataPathFunction joins segments into a metadata path.
+type MetadataPathFunction struct{}
+
+func (f *MetadataPathFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "metadata_path"
+}
+
+func (f *MetadataPathFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Build a metadata path from segments",
+		MarkdownDescription: "Joins segments into a path as expected by `nah_metadata.path`: it starts with `/` and has no empty segments. " +
+			"Segments may themselves contain `/`; duplicate and trailing slashes are removed and `.` and `..` are resolved. " +
+			"For example, `metadata_path(\"config/\", \"/app\", \"setting\")` returns `/config/app/setting`.",
+		VariadicParameter: function.StringParameter{
+			Name:                "segments",
+			MarkdownDescription: "The segments of the path.",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *MetadataPathFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var segments []string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &segments))
+	if resp.Error != nil {
+		return
+	}
+
+	p, err := metadataPath(segments...)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, p))
+}
+
+// metadataPath joins segments into a normalized metadata path.
+func metadataPath(segments ...string) (string, error) {
+	p := path.Clean("/" + strings.Join(segments, "/"))
+	if p == "/" {
+		return "", fmt.Errorf("a metadata path must name at least one segment, got %q", segments)
+	}
+	return p, nil
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestMetadataPathFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_8_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "joined" {
+  value = provider::nah::metadata_path("config/", "/app", "setting")
+}
+
+output "normalized" {
+  value = provider::nah::metadata_path("/config//app/../db/")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("joined", "/config/app/setting"),
+					resource.TestCheckOutput("normalized", "/config/db"),
+				),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::nah::metadata_path("/", "")
+}
+`,
+				ExpectError: regexp.MustCompile(`must name at least one segment`),
+			},
+		},
+	})
+}
@@ -4,17 +4,20 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
 
 var _ resource.Resource = &MetadataResource{}
 var _ resource.ResourceWithImportState = &MetadataResource{}
+var _ resource.ResourceWithValidateConfig = &MetadataResource{}
 
 func NewMetadataResource() resource.Resource {
 	return &MetadataResource{}
@@ -25,9 +28,12 @@ type MetadataResource struct {
 }
 
 type MetadataResourceModel struct {
-	ID    types.String `tfsdk:"id"`
-	Path  types.String `tfsdk:"path"`
-	Value types.String `tfsdk:"value"`
+	ID             types.String   `tfsdk:"id"`
+	Path           types.String   `tfsdk:"path"`
+	Value          types.String   `tfsdk:"value"`
+	ValueWO        types.String   `tfsdk:"value_wo"`
+	ValueWOVersion types.Int64    `tfsdk:"value_wo_version"`
+	Timeouts       timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *MetadataResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -51,10 +57,29 @@ func (r *MetadataResource) Schema(ctx context.Context, req resource.SchemaReques
 				MarkdownDescription: "The path for the metadata entry (e.g., `/config/app/setting`).",
 			},
 			"value": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "The value for the metadata entry.",
+				Optional:            true,
+				MarkdownDescription: "The value for the metadata entry. Exactly one of `value` or `value_wo` must be set.",
+			},
+			"value_wo": schema.StringAttribute{
+				Optional:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "The value for the metadata entry, never stored in the plan or state. It is only sent when `value_wo_version` changes, and changes made outside of Terraform are not detected.",
+			},
+			"value_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "The version of `value_wo`. Change it to send a new `value_wo`. Required with `value_wo`.",
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -75,6 +100,10 @@ func (r *MetadataResource) Configure(ctx context.Context, req resource.Configure
 	r.client = c
 }
 
+func (r *MetadataResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	validateWriteOnlyConfig(ctx, req.Config, "value", &resp.Diagnostics)
+}
+
 func (r *MetadataResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data MetadataResourceModel
 
@@ -83,16 +112,36 @@ func (r *MetadataResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	metadata, err := r.client.CreateMetadata(ctx, data.Path.ValueString(), data.Value.ValueString())
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	value := data.Value.ValueString()
+	if v := writeOnlyValue(ctx, req.Config, "value", data.ValueWOVersion, types.Int64Null(), &resp.Diagnostics); v != nil {
+		value = *v
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	metadata, err := r.client.CreateMetadata(ctx, data.Path.ValueString(), value)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create metadata: %s", err))
+		addClientError(&resp.Diagnostics, path.Empty(), path.Root("path"), "create metadata", err)
 		return
 	}
 
 	data.ID = types.StringValue(metadata.ID)
 	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	if data.ValueWOVersion.IsNull() {
+		data.Value = types.StringValue(metadata.Value)
+	}
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, metadata.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -104,15 +153,32 @@ func (r *MetadataResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	metadata, err := r.client.GetMetadata(ctx, data.ID.ValueString())
+	if client.IsNotFound(err) {
+		tflog.Warn(ctx, "Metadata no longer exists, removing from state", map[string]interface{}{"id": data.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read metadata: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read metadata", err)
 		return
 	}
 
 	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	if data.ValueWOVersion.IsNull() {
+		data.Value = types.StringValue(metadata.Value)
+	}
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, metadata.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -124,23 +190,48 @@ func (r *MetadataResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	var state MetadataResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	pathVal := data.Path.ValueString()
-	value := data.Value.ValueString()
 
 	updateReq := &client.UpdateMetadataRequest{
 		Path:  &pathVal,
-		Value: &value,
+		Value: data.Value.ValueStringPointer(),
+	}
+	if !data.ValueWOVersion.IsNull() {
+		updateReq.Value = writeOnlyValue(ctx, req.Config, "value", data.ValueWOVersion, state.ValueWOVersion, &resp.Diagnostics)
+	}
+
+	etag := getPrivateETag(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	metadata, err := r.client.UpdateMetadata(ctx, data.ID.ValueString(), updateReq)
+	metadata, err := r.client.UpdateMetadata(ctx, data.ID.ValueString(), updateReq, client.IfMatch(etag))
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update metadata: %s", err))
+		addClientError(&resp.Diagnostics, path.Empty(), path.Root("path"), "update metadata", err)
 		return
 	}
 
 	data.Path = types.StringValue(metadata.Path)
-	data.Value = types.StringValue(metadata.Value)
+	if data.ValueWOVersion.IsNull() {
+		data.Value = types.StringValue(metadata.Value)
+	}
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, metadata.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -152,9 +243,23 @@ func (r *MetadataResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
-	err := r.client.DeleteMetadata(ctx, data.ID.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete metadata: %s", err))
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	etag := getPrivateETag(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteMetadata(ctx, data.ID.ValueString(), client.IfMatch(etag))
+	if err != nil && !client.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "delete metadata", err)
 		return
 	}
 }
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestAccMetadataResource(t *testing.T) {
+	path := "/" + testAccName() + "/config"
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckMetadataDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccMetadataResourceConfig(path, "one"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_metadata.test", "path", path),
+					resource.TestCheckResourceAttr("nah_metadata.test", "value", "one"),
+					testAccCheckResourceID("nah_metadata.test", &id),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "nah_metadata.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccMetadataResourceConfig(path+"/v2", "two"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_metadata.test", "path", path+"/v2"),
+					resource.TestCheckResourceAttr("nah_metadata.test", "value", "two"),
+					resource.TestCheckResourceAttrPtr("nah_metadata.test", "id", &id),
+				),
+			},
+			// Drift is corrected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					value := "drifted"
+					_, err := c.UpdateMetadata(ctx, id, &client.UpdateMetadataRequest{Value: &value})
+					return err
+				}),
+				Config: testAccMetadataResourceConfig(path+"/v2", "two"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("nah_metadata.test", "value", "two"),
+			},
+			// Deletion outside of Terraform is detected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					return c.DeleteMetadata(ctx, id)
+				}),
+				Config: testAccMetadataResourceConfig(path+"/v2", "two"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_metadata.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_ifMatch(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to inject failures")
+	}
+	path := "/" + testAccName() + "/config"
+	var srv *nahtest.Server
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			srv = nahtest.NewServer(t)
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckMetadataDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataResourceConfig(path, "one"),
+			},
+			// A change made between refresh and update is reported
+			{
+				PreConfig:   func() { srv.InjectStatus("PATCH /v1/metadata/{id}", http.StatusPreconditionFailed, 1) },
+				Config:      testAccMetadataResourceConfig(path, "two"),
+				ExpectError: regexp.MustCompile(`Unable to update metadata: modified concurrently`),
+			},
+			// An update applied by an attempt whose response was lost is not
+			// mistaken for a concurrent change when retried
+			{
+				PreConfig: func() { srv.DropResponses("PATCH /v1/metadata/{id}", 1) },
+				Config:    testAccMetadataResourceConfig(path, "two"),
+				Check:     resource.TestCheckResourceAttr("nah_metadata.test", "value", "two"),
+			},
+		},
+	})
+}
+
+func TestAccMetadataResource_writeOnly(t *testing.T) {
+	path := "/" + testAccName() + "/secret"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_11_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckMetadataDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMetadataResourceWriteOnlyConfig(path, "one", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("nah_metadata.test", "value"),
+					resource.TestCheckNoResourceAttr("nah_metadata.test", "value_wo"),
+					resource.TestCheckResourceAttr("nah_metadata.test", "value_wo_version", "1"),
+					testAccCheckMetadataValue("nah_metadata.test", "one"),
+				),
+			},
+			// A new value is not sent until the version changes
+			{
+				Config: testAccMetadataResourceWriteOnlyConfig(path, "two", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: testAccCheckMetadataValue("nah_metadata.test", "one"),
+			},
+			{
+				Config: testAccMetadataResourceWriteOnlyConfig(path, "two", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_metadata.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckMetadataValue("nah_metadata.test", "two"),
+			},
+			// Switching back to a value stored in state
+			{
+				Config: testAccMetadataResourceConfig(path, "three"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_metadata.test", "value", "three"),
+					testAccCheckMetadataValue("nah_metadata.test", "three"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path     = %q
+  value    = "one"
+  value_wo = "two"
+}
+`, path),
+				ExpectError: regexp.MustCompile(`Only one of "value" or "value_wo" can be configured`),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path     = %q
+  value_wo = "one"
+}
+`, path),
+				ExpectError: regexp.MustCompile(`"value_wo" and "value_wo_version" must be configured together`),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path = %q
+}
+`, path),
+				ExpectError: regexp.MustCompile(`Exactly one of "value" or "value_wo" must be configured`),
+			},
+		},
+	})
+}
+
+// testAccCheckMetadataValue checks the value of the metadata entry at addr in
+// NahCloud, which is not in state when it is set through value_wo.
+func testAccCheckMetadataValue(addr, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		var id string
+		if err := testAccCheckResourceID(addr, &id)(s); err != nil {
+			return err
+		}
+		metadata, err := testAccClient().GetMetadata(context.Background(), id)
+		if err != nil {
+			return err
+		}
+		if metadata.Value != want {
+			return fmt.Errorf("expected value %q, got %q", want, metadata.Value)
+		}
+		return nil
+	}
+}
+
+func testAccCheckMetadataDestroy(s *terraform.State) error {
+	return testAccCheckDestroy("nah_metadata", func(ctx context.Context, c *client.Client, rs *terraform.ResourceState) error {
+		_, err := c.GetMetadata(ctx, rs.Primary.ID)
+		return err
+	})(s)
+}
+
+func testAccMetadataResourceConfig(path, value string) string {
+	return fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path  = %[1]q
+  value = %[2]q
+}
+`, path, value)
+}
+
+func testAccMetadataResourceWriteOnlyConfig(path, value string, version int) string {
+	return fmt.Sprintf(`
+resource "nah_metadata" "test" {
+  path             = %[1]q
+  value_wo         = %[2]q
+  value_wo_version = %[3]d
+}
+`, path, value, version)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
@@ -93,7 +94,7 @@ func (d *ObjectDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 
 	object, err := d.client.GetObject(ctx, data.BucketID.ValueString(), data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read object: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read object", err)
 		return
 	}
 
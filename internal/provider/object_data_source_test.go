@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccObjectDataSource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccObjectDataSourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nah_object.test", "id", "nah_object.test", "id"),
+					resource.TestCheckResourceAttrPair("data.nah_object.test", "bucket_id", "nah_bucket.test", "id"),
+					resource.TestCheckResourceAttr("data.nah_object.test", "path", "config.json"),
+					resource.TestCheckResourceAttr("data.nah_object.test", "content", "aGVsbG8="),
+					resource.TestCheckResourceAttrSet("data.nah_object.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.nah_object.test", "updated_at"),
+				),
+			},
+		},
+	})
+}
+
+func testAccObjectDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_bucket" "test" {
+  name = %[1]q
+}
+
+resource "nah_object" "test" {
+  bucket_id = nah_bucket.test.id
+  path      = "config.json"
+  content   = base64encode("hello")
+}
+
+data "nah_object" "test" {
+  bucket_id = nah_object.test.bucket_id
+  id        = nah_object.test.id
+}
+`, name)
+}
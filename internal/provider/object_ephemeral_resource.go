@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+var _ ephemeral.EphemeralResource = &ObjectEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &ObjectEphemeralResource{}
+
+func NewObjectEphemeralResource() ephemeral.EphemeralResource {
+	return &ObjectEphemeralResource{}
+}
+
+type ObjectEphemeralResource struct {
+	client *client.Client
+}
+
+type ObjectEphemeralResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	BucketID  types.String `tfsdk:"bucket_id"`
+	Path      types.String `tfsdk:"path"`
+	Content   types.String `tfsdk:"content"`
+	CreatedAt types.String `tfsdk:"created_at"`
+	UpdatedAt types.String `tfsdk:"updated_at"`
+}
+
+func (r *ObjectEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_object"
+}
+
+func (r *ObjectEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads a NahCloud storage object without storing its content in the plan or state. " +
+			"Use it instead of the `nah_object` data source for secrets.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The unique identifier of the object.",
+			},
+			"bucket_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The ID of the bucket this object belongs to.",
+			},
+			"path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The path of the object within the bucket.",
+			},
+			"content": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The content of the object (base64-encoded).",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The timestamp when the object was created.",
+			},
+			"updated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The timestamp when the object was last updated.",
+			},
+		},
+	}
+}
+
+func (r *ObjectEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = c
+}
+
+func (r *ObjectEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ObjectEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	object, err := r.client.GetObject(ctx, data.BucketID.ValueString(), data.ID.ValueString())
+	if err != nil {
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read object", err)
+		return
+	}
+
+	data.Path = types.StringValue(object.Path)
+	data.Content = types.StringValue(object.Content)
+	data.CreatedAt = types.StringValue(object.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	data.UpdatedAt = types.StringValue(object.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccObjectEphemeralResource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_10_0),
+		ProtoV6ProviderFactories: testAccEchoProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccObjectEphemeralResourceConfig(name),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("path"), knownvalue.StringExact("secret.txt")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("content"), knownvalue.StringExact("aHVudGVyMg==")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("created_at"), knownvalue.NotNull()),
+				},
+			},
+		},
+	})
+}
+
+func testAccObjectEphemeralResourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_bucket" "test" {
+  name = %[1]q
+}
+
+resource "nah_object" "test" {
+  bucket_id = nah_bucket.test.id
+  path      = "secret.txt"
+  content   = base64encode("hunter2")
+}
+
+ephemeral "nah_object" "test" {
+  bucket_id = nah_object.test.bucket_id
+  id        = nah_object.test.id
+}
+
+provider "echo" {
+  data = ephemeral.nah_object.test
+}
+
+resource "echo" "test" {}
+`, name)
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &ObjectIDFunction{}
+
+func NewObjectIDFunction() function.Function {
+	return &ObjectIDFunction{}
+}
+
+// ObjectIDFunction builds the composite ID of an object, as accepted by
+// terraform import of nah_object.
+type ObjectIDFunction struct{}
+
+func (f *ObjectIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "object_id"
+}
+
+func (f *ObjectIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build the composite ID of an object",
+		MarkdownDescription: "Returns the composite ID of an object, `bucket_id/object_id`, as accepted by `terraform import` of `nah_object`. Use `parse_object_id` to split it again.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "bucket_id",
+				MarkdownDescription: "The ID of the bucket containing the object.",
+			},
+			function.StringParameter{
+				Name:                "object_id",
+				MarkdownDescription: "The ID of the object.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ObjectIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var bucketID, objectID string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &bucketID, &objectID))
+	if resp.Error != nil {
+		return
+	}
+
+	id, err := objectCompositeID(bucketID, objectID)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, id))
+}
+
+// objectCompositeID returns the composite ID of an object.
+func objectCompositeID(bucketID, objectID string) (string, error) {
+	if bucketID == "" || objectID == "" {
+		return "", fmt.Errorf("bucket_id and object_id must not be empty, got %q and %q", bucketID, objectID)
+	}
+	if strings.Contains(bucketID, "/") {
+		return "", fmt.Errorf("bucket_id must not contain \"/\", got %q", bucketID)
+	}
+	return bucketID + "/" + objectID, nil
+}
+
+// parseObjectID splits the composite ID of an object into the IDs of its
+// bucket and of the object itself. It reports false if id is malformed.
+func parseObjectID(id string) (bucketID, objectID string, ok bool) {
+	bucketID, objectID, ok = strings.Cut(id, "/")
+	if !ok || bucketID == "" || objectID == "" {
+		return "", "", false
+	}
+	return bucketID, objectID, true
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestObjectIDFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_8_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+  value = provider::nah::object_id("bkt-1", "obj-1")
+}
+`,
+				Check: resource.TestCheckOutput("test", "bkt-1/obj-1"),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::nah::object_id("", "obj-1")
+}
+`,
+				ExpectError: regexp.MustCompile(`must not be empty`),
+			},
+		},
+	})
+}
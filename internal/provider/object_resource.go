@@ -4,17 +4,20 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hypertf/terraform-provider-nah/internal/client"
 )
 
 var _ resource.Resource = &ObjectResource{}
 var _ resource.ResourceWithImportState = &ObjectResource{}
+var _ resource.ResourceWithValidateConfig = &ObjectResource{}
 
 func NewObjectResource() resource.Resource {
 	return &ObjectResource{}
@@ -25,10 +28,13 @@ type ObjectResource struct {
 }
 
 type ObjectResourceModel struct {
-	ID       types.String `tfsdk:"id"`
-	BucketID types.String `tfsdk:"bucket_id"`
-	Path     types.String `tfsdk:"path"`
-	Content  types.String `tfsdk:"content"`
+	ID               types.String   `tfsdk:"id"`
+	BucketID         types.String   `tfsdk:"bucket_id"`
+	Path             types.String   `tfsdk:"path"`
+	Content          types.String   `tfsdk:"content"`
+	ContentWO        types.String   `tfsdk:"content_wo"`
+	ContentWOVersion types.Int64    `tfsdk:"content_wo_version"`
+	Timeouts         timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *ObjectResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -59,10 +65,29 @@ func (r *ObjectResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				MarkdownDescription: "The path of the object within the bucket.",
 			},
 			"content": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "The content of the object (base64-encoded).",
+				Optional:            true,
+				MarkdownDescription: "The content of the object (base64-encoded). Exactly one of `content` or `content_wo` must be set.",
+			},
+			"content_wo": schema.StringAttribute{
+				Optional:            true,
+				WriteOnly:           true,
+				Sensitive:           true,
+				MarkdownDescription: "The content of the object (base64-encoded), never stored in the plan or state. It is only sent when `content_wo_version` changes, and changes made outside of Terraform are not detected.",
+			},
+			"content_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "The version of `content_wo`. Change it to send a new `content_wo`. Required with `content_wo`.",
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -83,6 +108,10 @@ func (r *ObjectResource) Configure(ctx context.Context, req resource.ConfigureRe
 	r.client = c
 }
 
+func (r *ObjectResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	validateWriteOnlyConfig(ctx, req.Config, "content", &resp.Diagnostics)
+}
+
 func (r *ObjectResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ObjectResourceModel
 
@@ -91,22 +120,40 @@ func (r *ObjectResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	createReq := &client.CreateObjectRequest{
 		Path:    data.Path.ValueString(),
 		Content: data.Content.ValueString(),
 	}
+	if v := writeOnlyValue(ctx, req.Config, "content", data.ContentWOVersion, types.Int64Null(), &resp.Diagnostics); v != nil {
+		createReq.Content = *v
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	object, err := r.client.CreateObject(ctx, data.BucketID.ValueString(), createReq)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create object: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("bucket_id"), path.Root("path"), "create object", err)
 		return
 	}
 
 	data.ID = types.StringValue(object.ID)
 	data.BucketID = types.StringValue(object.BucketID)
 	data.Path = types.StringValue(object.Path)
-	data.Content = types.StringValue(object.Content)
+	if data.ContentWOVersion.IsNull() {
+		data.Content = types.StringValue(object.Content)
+	}
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, object.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -118,16 +165,33 @@ func (r *ObjectResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	object, err := r.client.GetObject(ctx, data.BucketID.ValueString(), data.ID.ValueString())
+	if client.IsNotFound(err) {
+		tflog.Warn(ctx, "Object no longer exists, removing from state", map[string]interface{}{"id": data.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read object: %s", err))
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "read object", err)
 		return
 	}
 
 	data.BucketID = types.StringValue(object.BucketID)
 	data.Path = types.StringValue(object.Path)
-	data.Content = types.StringValue(object.Content)
+	if data.ContentWOVersion.IsNull() {
+		data.Content = types.StringValue(object.Content)
+	}
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, object.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -139,23 +203,48 @@ func (r *ObjectResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	var state ObjectResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	pathVal := data.Path.ValueString()
-	content := data.Content.ValueString()
 
 	updateReq := &client.UpdateObjectRequest{
 		Path:    &pathVal,
-		Content: &content,
+		Content: data.Content.ValueStringPointer(),
+	}
+	if !data.ContentWOVersion.IsNull() {
+		updateReq.Content = writeOnlyValue(ctx, req.Config, "content", data.ContentWOVersion, state.ContentWOVersion, &resp.Diagnostics)
 	}
 
-	object, err := r.client.UpdateObject(ctx, data.BucketID.ValueString(), data.ID.ValueString(), updateReq)
+	etag := getPrivateETag(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	object, err := r.client.UpdateObject(ctx, data.BucketID.ValueString(), data.ID.ValueString(), updateReq, client.IfMatch(etag))
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update object: %s", err))
+		addClientError(&resp.Diagnostics, path.Empty(), path.Root("path"), "update object", err)
 		return
 	}
 
 	data.Path = types.StringValue(object.Path)
-	data.Content = types.StringValue(object.Content)
+	if data.ContentWOVersion.IsNull() {
+		data.Content = types.StringValue(object.Content)
+	}
 
+	resp.Diagnostics.Append(setPrivateETag(ctx, resp.Private, object.ETag)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -167,13 +256,39 @@ func (r *ObjectResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	err := r.client.DeleteObject(ctx, data.BucketID.ValueString(), data.ID.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete object: %s", err))
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	etag := getPrivateETag(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteObject(ctx, data.BucketID.ValueString(), data.ID.ValueString(), client.IfMatch(etag))
+	if err != nil && !client.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, path.Root("id"), path.Empty(), "delete object", err)
 		return
 	}
 }
 
 func (r *ObjectResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	// Objects are addressed within their bucket, so the import ID must
+	// carry both IDs.
+	bucketID, objectID, ok := parseObjectID(req.ID)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected an import ID of the form bucket_id/object_id, got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket_id"), bucketID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), objectID)...)
 }
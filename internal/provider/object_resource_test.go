@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+func TestAccObjectResource(t *testing.T) {
+	name := testAccName()
+	var bucketID, id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckObjectDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccObjectResourceConfig(name, "a", "config.json", "hello"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("nah_object.test", "bucket_id", "nah_bucket.a", "id"),
+					resource.TestCheckResourceAttr("nah_object.test", "path", "config.json"),
+					resource.TestCheckResourceAttr("nah_object.test", "content", "aGVsbG8="),
+					testAccCheckResourceID("nah_bucket.a", &bucketID),
+					testAccCheckResourceID("nah_object.test", &id),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "nah_object.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(*terraform.State) (string, error) {
+					return bucketID + "/" + id, nil
+				},
+			},
+			// Update in place
+			{
+				Config: testAccObjectResourceConfig(name, "a", "config/v2.json", "world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_object.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_object.test", "path", "config/v2.json"),
+					resource.TestCheckResourceAttr("nah_object.test", "content", "d29ybGQ="),
+					resource.TestCheckResourceAttrPtr("nah_object.test", "id", &id),
+				),
+			},
+			// Drift is corrected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					content := "ZHJpZnRlZA=="
+					_, err := c.UpdateObject(ctx, bucketID, id, &client.UpdateObjectRequest{Content: &content})
+					return err
+				}),
+				Config: testAccObjectResourceConfig(name, "a", "config/v2.json", "world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_object.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("nah_object.test", "content", "d29ybGQ="),
+			},
+			// Changing bucket_id replaces the object
+			{
+				Config: testAccObjectResourceConfig(name, "b", "config/v2.json", "world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_object.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("nah_object.test", "bucket_id", "nah_bucket.b", "id"),
+					testAccCheckResourceID("nah_bucket.b", &bucketID),
+					testAccCheckResourceID("nah_object.test", &id),
+				),
+			},
+			// Deletion outside of Terraform is detected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					return c.DeleteObject(ctx, bucketID, id)
+				}),
+				Config: testAccObjectResourceConfig(name, "b", "config/v2.json", "world"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_object.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccObjectResource_invalidImportID(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:        testAccObjectResourceConfig(testAccName(), "a", "config.json", "hello"),
+				ResourceName:  "nah_object.test",
+				ImportState:   true,
+				ImportStateId: "obj-0123456789ab",
+				ExpectError:   regexp.MustCompile(`Expected an import ID of the form bucket_id/object_id`),
+			},
+		},
+	})
+}
+
+func TestAccObjectResource_writeOnly(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_11_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccObjectResourceWriteOnlyConfig(name, "one", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("nah_object.test", "content"),
+					resource.TestCheckNoResourceAttr("nah_object.test", "content_wo"),
+					resource.TestCheckResourceAttr("nah_object.test", "content_wo_version", "1"),
+					testAccCheckObjectContent("nah_object.test", "b25l"),
+				),
+			},
+			// New content is not sent until the version changes
+			{
+				Config: testAccObjectResourceWriteOnlyConfig(name, "two", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: testAccCheckObjectContent("nah_object.test", "b25l"),
+			},
+			{
+				Config: testAccObjectResourceWriteOnlyConfig(name, "two", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_object.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckObjectContent("nah_object.test", "dHdv"),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "nah_bucket" "test" {
+  name = %q
+}
+
+resource "nah_object" "test" {
+  bucket_id = nah_bucket.test.id
+  path      = "secret.txt"
+}
+`, name),
+				ExpectError: regexp.MustCompile(`Exactly one of "content" or "content_wo" must be configured`),
+			},
+		},
+	})
+}
+
+// testAccCheckObjectContent checks the content of the object at addr in
+// NahCloud, which is not in state when it is set through content_wo.
+func testAccCheckObjectContent(addr, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[addr]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", addr)
+		}
+		object, err := testAccClient().GetObject(context.Background(), rs.Primary.Attributes["bucket_id"], rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if object.Content != want {
+			return fmt.Errorf("expected content %q, got %q", want, object.Content)
+		}
+		return nil
+	}
+}
+
+func testAccCheckObjectDestroy(s *terraform.State) error {
+	return testAccCheckDestroy("nah_object", func(ctx context.Context, c *client.Client, rs *terraform.ResourceState) error {
+		_, err := c.GetObject(ctx, rs.Primary.Attributes["bucket_id"], rs.Primary.ID)
+		return err
+	})(s)
+}
+
+// testAccObjectResourceConfig returns a configuration with two buckets, "a"
+// and "b", and an object in the given one.
+func testAccObjectResourceConfig(name, bucket, path, content string) string {
+	return fmt.Sprintf(`
+resource "nah_bucket" "a" {
+  name = "%[1]s-a"
+}
+
+resource "nah_bucket" "b" {
+  name = "%[1]s-b"
+}
+
+resource "nah_object" "test" {
+  bucket_id = nah_bucket.%[2]s.id
+  path      = %[3]q
+  content   = base64encode(%[4]q)
+}
+`, name, bucket, path, content)
+}
+
+func testAccObjectResourceWriteOnlyConfig(name, content string, version int) string {
+	return fmt.Sprintf(`
+resource "nah_bucket" "test" {
+  name = %[1]q
+}
+
+resource "nah_object" "test" {
+  bucket_id          = nah_bucket.test.id
+  path               = "secret.txt"
+  content_wo         = base64encode(%[2]q)
+  content_wo_version = %[3]d
+}
+`, name, content, version)
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &ParseObjectIDFunction{}
+
+func NewParseObjectIDFunction() function.Function {
+	return &ParseObjectIDFunction{}
+}
+
+// ParseObjectIDFunction splits the composite ID of an object built by
+// ObjectIDFunction.
+type ParseObjectIDFunction struct{}
+
+// objectIDAttributeTypes are the attributes of the object returned by
+// parse_object_id.
+var objectIDAttributeTypes = map[string]attr.Type{
+	"bucket_id": types.StringType,
+	"object_id": types.StringType,
+}
+
+func (f *ParseObjectIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_object_id"
+}
+
+func (f *ParseObjectIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Split the composite ID of an object",
+		MarkdownDescription: "Splits the composite ID of an object, `bucket_id/object_id`, into an object with the `bucket_id` and `object_id` attributes.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "id",
+				MarkdownDescription: "The composite ID of the object, e.g. as returned by `object_id`.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: objectIDAttributeTypes,
+		},
+	}
+}
+
+func (f *ParseObjectIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var id string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &id))
+	if resp.Error != nil {
+		return
+	}
+
+	bucketID, objectID, ok := parseObjectID(id)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Expected an ID of the form bucket_id/object_id, got: %q", id))
+		return
+	}
+
+	result, diags := types.ObjectValue(objectIDAttributeTypes, map[string]attr.Value{
+		"bucket_id": types.StringValue(bucketID),
+		"object_id": types.StringValue(objectID),
+	})
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestParseObjectIDFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_8_0),
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  parsed = provider::nah::parse_object_id("bkt-1/obj-1")
+}
+
+output "bucket_id" {
+  value = local.parsed.bucket_id
+}
+
+output "object_id" {
+  value = local.parsed.object_id
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("bucket_id", "bkt-1"),
+					resource.TestCheckOutput("object_id", "obj-1"),
+				),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::nah::parse_object_id("obj-1")
+}
+`,
+				ExpectError: regexp.MustCompile(`Expected an ID of the form bucket_id/object_id`),
+			},
+		},
+	})
+}
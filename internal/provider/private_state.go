@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// privateETagKey is the private state key holding the ETag of the version of
+// a resource last seen by the provider. It is sent as If-Match on updates and
+// deletes so that concurrent writers are detected instead of overwritten.
+const privateETagKey = "etag"
+
+type privateStateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+type privateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// getPrivateETag returns the ETag stored in private state, or an empty
+// string if there is none.
+func getPrivateETag(ctx context.Context, private privateStateGetter, diags *diag.Diagnostics) string {
+	raw, d := private.GetKey(ctx, privateETagKey)
+	diags.Append(d...)
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var etag string
+	if err := json.Unmarshal(raw, &etag); err != nil {
+		diags.AddError("Invalid Private State", "Unable to decode the stored ETag: "+err.Error())
+		return ""
+	}
+	return etag
+}
+
+// setPrivateETag stores etag in private state, removing the key when etag is
+// empty.
+func setPrivateETag(ctx context.Context, private privateStateSetter, etag string) diag.Diagnostics {
+	if etag == "" {
+		return private.SetKey(ctx, privateETagKey, nil)
+	}
+
+	raw, err := json.Marshal(etag)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid Private State", "Unable to encode the ETag: "+err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, privateETagKey, raw)
+}
+
+// privateTokenKey is the private data key of a nah_token ephemeral resource,
+// holding what Renew and Close need to know about the token.
+const privateTokenKey = "token"
+
+type privateToken struct {
+	ID         string `json:"id"`
+	TTLSeconds int    `json:"ttl_seconds"`
+}
+
+// getPrivateToken returns the token stored in private data.
+func getPrivateToken(ctx context.Context, private privateStateGetter, diags *diag.Diagnostics) privateToken {
+	var token privateToken
+
+	raw, d := private.GetKey(ctx, privateTokenKey)
+	diags.Append(d...)
+	if diags.HasError() {
+		return token
+	}
+	if err := json.Unmarshal(raw, &token); err != nil {
+		diags.AddError("Invalid Private State", "Unable to decode the stored token: "+err.Error())
+	}
+	return token
+}
+
+// setPrivateToken stores token in private data.
+func setPrivateToken(ctx context.Context, private privateStateSetter, token privateToken) diag.Diagnostics {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid Private State", "Unable to encode the token: "+err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, privateTokenKey, raw)
+}
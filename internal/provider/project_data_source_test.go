@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccProjectDataSource(t *testing.T) {
+	name := testAccName()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProjectDataSourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nah_project.test", "id", "nah_project.test", "id"),
+					resource.TestCheckResourceAttr("data.nah_project.test", "name", name),
+					resource.TestCheckResourceAttrSet("data.nah_project.test", "created_at"),
+					resource.TestCheckResourceAttrSet("data.nah_project.test", "updated_at"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProjectDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_project" "test" {
+  name = %[1]q
+}
+
+data "nah_project" "test" {
+  id = nah_project.test.id
+}
+`, name)
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestAccProjectResource(t *testing.T) {
+	name := testAccName()
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckProjectDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccProjectResourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_project.test", "name", name),
+					resource.TestCheckResourceAttrSet("nah_project.test", "id"),
+					testAccCheckResourceID("nah_project.test", &id),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "nah_project.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update in place
+			{
+				Config: testAccProjectResourceConfig(name + "-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_project.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_project.test", "name", name+"-renamed"),
+					resource.TestCheckResourceAttrPtr("nah_project.test", "id", &id),
+				),
+			},
+			// Drift is corrected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					_, err := c.UpdateProject(ctx, id, name+"-drifted")
+					return err
+				}),
+				Config: testAccProjectResourceConfig(name + "-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_project.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("nah_project.test", "name", name+"-renamed"),
+			},
+			// Deletion outside of Terraform is detected
+			{
+				PreConfig: testAccOutOfBand(t, func(ctx context.Context, c *client.Client) error {
+					return c.DeleteProject(ctx, id)
+				}),
+				Config: testAccProjectResourceConfig(name + "-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nah_project.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccProjectResource_timeouts(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to inject latency")
+	}
+	name := testAccName()
+	var srv *nahtest.Server
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			srv = nahtest.NewServer(t)
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckProjectDestroy,
+		Steps: []resource.TestStep{
+			// A slow create fails once the create timeout expires
+			{
+				PreConfig:   func() { srv.InjectLatency("POST /v1/projects", time.Minute) },
+				Config:      testAccProjectResourceTimeoutsConfig(name, "200ms"),
+				ExpectError: regexp.MustCompile(`Unable to create project: timed out`),
+			},
+			// The same create succeeds within a longer timeout
+			{
+				PreConfig: func() {
+					srv.ClearHooks()
+					srv.InjectLatency("POST /v1/projects", 200*time.Millisecond)
+				},
+				Config: testAccProjectResourceTimeoutsConfig(name, "5s"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_project.test", "name", name),
+					resource.TestCheckResourceAttr("nah_project.test", "timeouts.create", "5s"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProjectResourceTimeoutsConfig(name, create string) string {
+	return fmt.Sprintf(`
+resource "nah_project" "test" {
+  name = %q
+
+  timeouts {
+    create = %q
+  }
+}
+`, name, create)
+}
+
+func testAccCheckProjectDestroy(s *terraform.State) error {
+	return testAccCheckDestroy("nah_project", func(ctx context.Context, c *client.Client, rs *terraform.ResourceState) error {
+		_, err := c.GetProject(ctx, rs.Primary.ID)
+		return err
+	})(s)
+}
+
+func testAccProjectResourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nah_project" "test" {
+  name = %[1]q
+}
+`, name)
+}
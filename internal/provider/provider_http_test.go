@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
+)
+
+func TestAccProvider_proxyURL(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to act as the proxy")
+	}
+	name := testAccName()
+	srv := nahtest.NewServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			// Nothing listens on port 1, so requests only succeed if they
+			// go through proxy_url rather than the environment's proxy.
+			t.Setenv("HTTP_PROXY", "http://127.0.0.1:1")
+			t.Setenv("HTTPS_PROXY", "http://127.0.0.1:1")
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// The endpoint does not resolve: only the proxy can reach it.
+				Config: fmt.Sprintf(`
+provider "nah" {
+  endpoint  = "http://nahcloud.invalid"
+  proxy_url = "http://proxy-user:proxy-password@%s"
+}
+%s`, srv.Listener.Addr(), testAccProjectResourceConfig(name)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_project.test", "name", name),
+					testAccCheckRequestHeader(srv, "Proxy-Authorization",
+						"Basic "+base64.StdEncoding.EncodeToString([]byte("proxy-user:proxy-password"))),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_extraHeaders(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to inspect request headers")
+	}
+	name := testAccName()
+	srv := nahtest.NewServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckProjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "nah" {
+  extra_headers = {
+    "X-Tenant" = "acme"
+  }
+
+  sensitive_extra_headers = {
+    "X-Gateway-Key" = "gateway-s3cret"
+  }
+}
+%s`, testAccProjectResourceConfig(name)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_project.test", "name", name),
+					testAccCheckRequestHeader(srv, "X-Tenant", "acme"),
+					testAccCheckRequestHeader(srv, "X-Gateway-Key", "gateway-s3cret"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_userAgentSuffix(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to inspect request headers")
+	}
+	name := testAccName()
+	srv := nahtest.NewServer(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckProjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "nah" {
+  user_agent_suffix = "pipeline/deploy-42"
+}
+%s`, testAccProjectResourceConfig(name)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nah_project.test", "name", name),
+					func(s *terraform.State) error {
+						want := regexp.MustCompile(`^terraform-provider-nah/test terraform/\d+\.\d+\.\d+\S* \(\+https://registry\.terraform\.io/providers/hypertf/nah\) pipeline/deploy-42$`)
+						for _, r := range srv.Requests() {
+							if got := r.Header.Get("User-Agent"); !want.MatchString(got) {
+								return fmt.Errorf("%s %s: unexpected User-Agent %q", r.Method, r.Path, got)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_responseHeaderTimeout(t *testing.T) {
+	if os.Getenv("NAH_ENDPOINT") != "" {
+		t.Skip("requires the fake server to delay responses")
+	}
+	name := testAccName()
+	srv := nahtest.NewServer(t)
+	srv.InjectLatency("GET /v1/projects/{id}", 500*time.Millisecond)
+	provider := `
+provider "nah" {
+  response_header_timeout = "100ms"
+
+  retry {
+    max_attempts = 1
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			t.Setenv("NAH_ENDPOINT", srv.URL)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckProjectDestroy,
+		Steps: []resource.TestStep{
+			// Data sources have no timeouts, so the response header timeout
+			// applies.
+			{
+				Config:      provider + testAccProjectDataSourceConfig(name),
+				ExpectError: regexp.MustCompile(`timeout\s+awaiting\s+response\s+headers`),
+			},
+			// Resources wait as long as their timeouts allow.
+			{
+				Config: provider + testAccProjectResourceConfig(name),
+				Check:  resource.TestCheckResourceAttr("nah_project.test", "name", name),
+			},
+		},
+	})
+}
+
+// testAccCheckRequestHeader checks that every request srv has received
+// carried the header name with the value want.
+func testAccCheckRequestHeader(srv *nahtest.Server, name, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		requests := srv.Requests()
+		if len(requests) == 0 {
+			return fmt.Errorf("expected requests to the server")
+		}
+		for _, r := range requests {
+			if got := r.Header.Get(name); got != want {
+				return fmt.Errorf("%s %s: expected %s header %q, got %q", r.Method, r.Path, name, want, got)
+			}
+		}
+		return nil
+	}
+}
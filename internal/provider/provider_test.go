@@ -1,9 +1,123 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+	"github.com/hypertf/terraform-provider-nah/internal/nahtest"
 )
 
-func TestPlaceholder(t *testing.T) {
-	// Placeholder test to satisfy go test
+// testAccNamePrefix is the prefix of the names of all objects created by
+// acceptance tests.
+const testAccNamePrefix = "tf-acc-test"
+
+// testAccProtoV6ProviderFactories are used to instantiate a provider during
+// acceptance testing. The factory function is called for each Terraform CLI
+// command executed to create a provider server to which the CLI can reattach.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"nah": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccEchoProviderFactories add the echo provider, which copies its data
+// argument to the state of an echo resource, so that tests can inspect the
+// results of ephemeral resources.
+func testAccEchoProviderFactories() map[string]func() (tfprotov6.ProviderServer, error) {
+	factories := map[string]func() (tfprotov6.ProviderServer, error){
+		"echo": echoprovider.NewProviderServer(),
+	}
+	for name, factory := range testAccProtoV6ProviderFactories {
+		factories[name] = factory
+	}
+	return factories
+}
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+// testAccPreCheck points the provider at a fake NahCloud server started for
+// the test, unless NAH_ENDPOINT selects a real one.
+func testAccPreCheck(t *testing.T) {
+	t.Helper()
+
+	if os.Getenv("NAH_ENDPOINT") == "" {
+		srv := nahtest.NewServer(t)
+		t.Setenv("NAH_ENDPOINT", srv.URL)
+	}
+}
+
+// testAccTerraformVersionChecks skips a test on Terraform versions older than
+// minVersion, which lack the features it exercises. CI sets
+// NAH_ACC_REQUIRE_TERRAFORM_FEATURES with the latest Terraform version, where
+// such tests fail instead, so that they cannot silently stop running.
+func testAccTerraformVersionChecks(minVersion *version.Version) []tfversion.TerraformVersionCheck {
+	if os.Getenv("NAH_ACC_REQUIRE_TERRAFORM_FEATURES") != "" {
+		return []tfversion.TerraformVersionCheck{tfversion.RequireAbove(minVersion)}
+	}
+	return []tfversion.TerraformVersionCheck{tfversion.SkipBelow(minVersion)}
+}
+
+// testAccClient returns a client for the server used by the provider, to
+// inspect or change objects outside of Terraform.
+func testAccClient() *client.Client {
+	return client.NewClient(os.Getenv("NAH_ENDPOINT"), os.Getenv("NAH_TOKEN"))
+}
+
+// testAccName returns a unique name for an object created by a test.
+func testAccName() string {
+	return acctest.RandomWithPrefix(testAccNamePrefix)
+}
+
+// testAccCheckResourceID stores the ID of the resource at addr in id.
+func testAccCheckResourceID(addr string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[addr]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", addr)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+// testAccCheckDestroy verifies that every resource of the given type in the
+// state has been deleted, using get to look each one up.
+func testAccCheckDestroy(resourceType string, get func(ctx context.Context, c *client.Client, rs *terraform.ResourceState) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		c := testAccClient()
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			err := get(context.Background(), c, rs)
+			if err == nil {
+				return fmt.Errorf("%s %s still exists", resourceType, rs.Primary.ID)
+			}
+			if !client.IsNotFound(err) {
+				return fmt.Errorf("checking %s %s: %w", resourceType, rs.Primary.ID, err)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccOutOfBand runs fn with a client during PreConfig, to simulate changes
+// made outside of Terraform, and fails the test if it returns an error.
+func testAccOutOfBand(t *testing.T, fn func(ctx context.Context, c *client.Client) error) func() {
+	return func() {
+		if err := fn(t.Context(), testAccClient()); err != nil {
+			t.Fatalf("changing objects out-of-band: %s", err)
+		}
+	}
 }
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+// Sweepers delete objects left behind by failed acceptance test runs on the
+// server selected by NAH_ENDPOINT and NAH_TOKEN. Run them with:
+//
+//	go test ./internal/provider -v -sweep=all
+//
+// Only objects whose name or path starts with testAccNamePrefix are deleted,
+// along with everything inside test projects and buckets.
+func init() {
+	resource.AddTestSweepers("nah_object", &resource.Sweeper{
+		Name: "nah_object",
+		F:    sweepObjects,
+	})
+	resource.AddTestSweepers("nah_bucket", &resource.Sweeper{
+		Name:         "nah_bucket",
+		Dependencies: []string{"nah_object"},
+		F:            sweepBuckets,
+	})
+	resource.AddTestSweepers("nah_instance", &resource.Sweeper{
+		Name: "nah_instance",
+		F:    sweepInstances,
+	})
+	resource.AddTestSweepers("nah_project", &resource.Sweeper{
+		Name:         "nah_project",
+		Dependencies: []string{"nah_instance"},
+		F:            sweepProjects,
+	})
+	resource.AddTestSweepers("nah_metadata", &resource.Sweeper{
+		Name: "nah_metadata",
+		F:    sweepMetadata,
+	})
+}
+
+func sweepClient() *client.Client {
+	return client.NewClient(os.Getenv("NAH_ENDPOINT"), os.Getenv("NAH_TOKEN"))
+}
+
+func isSweepable(name string) bool {
+	return strings.HasPrefix(name, testAccNamePrefix)
+}
+
+// sweepDelete deletes each ID with del, treating objects that are already
+// gone as deleted, and returns all other errors.
+func sweepDelete(ctx context.Context, kind string, ids []string, del func(ctx context.Context, id string) error) error {
+	var errs []error
+	for _, id := range ids {
+		if err := del(ctx, id); err != nil && !client.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting %s %s: %w", kind, id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// testBuckets returns the buckets created by acceptance tests.
+func testBuckets(ctx context.Context, c *client.Client) ([]string, error) {
+	var ids []string
+	for bucket, err := range c.AllBuckets(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("listing buckets: %w", err)
+		}
+		if isSweepable(bucket.Name) {
+			ids = append(ids, bucket.ID)
+		}
+	}
+	return ids, nil
+}
+
+// testProjects returns the projects created by acceptance tests.
+func testProjects(ctx context.Context, c *client.Client) ([]string, error) {
+	var ids []string
+	for project, err := range c.AllProjects(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("listing projects: %w", err)
+		}
+		if isSweepable(project.Name) {
+			ids = append(ids, project.ID)
+		}
+	}
+	return ids, nil
+}
+
+func sweepObjects(string) error {
+	ctx := context.Background()
+	c := sweepClient()
+
+	buckets, err := testBuckets(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, bucketID := range buckets {
+		var ids []string
+		for object, err := range c.AllObjects(ctx, bucketID, "") {
+			if err != nil {
+				return fmt.Errorf("listing objects in bucket %s: %w", bucketID, err)
+			}
+			ids = append(ids, object.ID)
+		}
+		errs = append(errs, sweepDelete(ctx, "object", ids, func(ctx context.Context, id string) error {
+			return c.DeleteObject(ctx, bucketID, id)
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+func sweepBuckets(string) error {
+	ctx := context.Background()
+	c := sweepClient()
+
+	ids, err := testBuckets(ctx, c)
+	if err != nil {
+		return err
+	}
+	return sweepDelete(ctx, "bucket", ids, func(ctx context.Context, id string) error {
+		return c.DeleteBucket(ctx, id)
+	})
+}
+
+func sweepInstances(string) error {
+	ctx := context.Background()
+	c := sweepClient()
+
+	projects, err := testProjects(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// Instances in test projects must go for the projects to be deleted,
+	// whatever their name.
+	var ids []string
+	for instance, err := range c.AllInstances(ctx, "") {
+		if err != nil {
+			return fmt.Errorf("listing instances: %w", err)
+		}
+		if isSweepable(instance.Name) || slices.Contains(projects, instance.ProjectID) {
+			ids = append(ids, instance.ID)
+		}
+	}
+	return sweepDelete(ctx, "instance", ids, func(ctx context.Context, id string) error {
+		return c.DeleteInstance(ctx, id)
+	})
+}
+
+func sweepProjects(string) error {
+	ctx := context.Background()
+	c := sweepClient()
+
+	ids, err := testProjects(ctx, c)
+	if err != nil {
+		return err
+	}
+	return sweepDelete(ctx, "project", ids, func(ctx context.Context, id string) error {
+		return c.DeleteProject(ctx, id)
+	})
+}
+
+func sweepMetadata(string) error {
+	ctx := context.Background()
+	c := sweepClient()
+
+	var ids []string
+	for metadata, err := range c.AllMetadata(ctx, "/"+testAccNamePrefix) {
+		if err != nil {
+			return fmt.Errorf("listing metadata: %w", err)
+		}
+		ids = append(ids, metadata.ID)
+	}
+	return sweepDelete(ctx, "metadata", ids, func(ctx context.Context, id string) error {
+		return c.DeleteMetadata(ctx, id)
+	})
+}
@@ -0,0 +1,13 @@
+package provider
+
+import "time"
+
+// Default timeouts of resource operations and action invocations, used when
+// the timeouts block of a resource or action does not set them.
+const (
+	defaultCreateTimeout = 20 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 20 * time.Minute
+	defaultDeleteTimeout = 20 * time.Minute
+	defaultInvokeTimeout = 20 * time.Minute
+)
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hypertf/terraform-provider-nah/internal/client"
+)
+
+var _ ephemeral.EphemeralResource = &TokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &TokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithRenew = &TokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithClose = &TokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &TokenEphemeralResource{}
+
+// minTokenTTL and maxTokenTTL bound the ttl NahCloud accepts for a token.
+const (
+	minTokenTTL = time.Minute
+	maxTokenTTL = 24 * time.Hour
+)
+
+func NewTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &TokenEphemeralResource{}
+}
+
+type TokenEphemeralResource struct {
+	client *client.Client
+}
+
+type TokenEphemeralResourceModel struct {
+	Scopes    types.List   `tfsdk:"scopes"`
+	TTL       types.String `tfsdk:"ttl"`
+	ID        types.String `tfsdk:"id"`
+	Token     types.String `tfsdk:"token"`
+	ExpiresAt types.String `tfsdk:"expires_at"`
+}
+
+func (r *TokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_token"
+}
+
+func (r *TokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Exchanges the provider credential for a short-lived NahCloud API token. " +
+			"The token is renewed while Terraform runs and revoked when it is no longer needed, and is never stored in the plan or state.",
+
+		Attributes: map[string]schema.Attribute{
+			"scopes": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "The scopes to grant the token, among `read` and `write`. Defaults to all scopes.",
+			},
+			"ttl": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "How long the token is valid for, and is extended by on every renewal, as a Go duration string of whole seconds between `1m` and `24h`. Defaults to `1h`.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The unique identifier of the token.",
+			},
+			"token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The token, to be sent as a bearer token.",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the token expires unless renewed, in RFC 3339 format.",
+			},
+		},
+	}
+}
+
+func (r *TokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = c
+}
+
+func (r *TokenEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var ttl types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ttl"), &ttl)...)
+	if resp.Diagnostics.HasError() || ttl.IsUnknown() {
+		return
+	}
+	tokenTTLSeconds(ttl, &resp.Diagnostics)
+}
+
+func (r *TokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data TokenEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createReq := &client.CreateTokenRequest{}
+	resp.Diagnostics.Append(data.Scopes.ElementsAs(ctx, &createReq.Scopes, false)...)
+	createReq.TTLSeconds = tokenTTLSeconds(data.TTL, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token, err := r.client.CreateToken(ctx, createReq)
+	if err != nil {
+		addClientError(&resp.Diagnostics, path.Empty(), path.Empty(), "create token", err)
+		return
+	}
+
+	scopes, diags := types.ListValueFrom(ctx, types.StringType, token.Scopes)
+	resp.Diagnostics.Append(diags...)
+	data.Scopes = scopes
+	data.ID = types.StringValue(token.ID)
+	data.Token = types.StringValue(token.Secret)
+	data.ExpiresAt = types.StringValue(token.ExpiresAt.Format(time.RFC3339))
+
+	resp.Diagnostics.Append(setPrivateToken(ctx, resp.Private, privateToken{ID: token.ID, TTLSeconds: createReq.TTLSeconds})...)
+	resp.RenewAt = tokenRenewAt(token)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+func (r *TokenEphemeralResource) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	private := getPrivateToken(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token, err := r.client.RenewToken(ctx, private.ID, private.TTLSeconds)
+	if err != nil {
+		addClientError(&resp.Diagnostics, path.Empty(), path.Empty(), "renew token", err)
+		return
+	}
+
+	tflog.Debug(ctx, "Renewed token", map[string]interface{}{
+		"id":         token.ID,
+		"expires_at": token.ExpiresAt.Format(time.RFC3339),
+	})
+	resp.RenewAt = tokenRenewAt(token)
+}
+
+func (r *TokenEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	private := getPrivateToken(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A token that is not found has already expired.
+	err := r.client.RevokeToken(ctx, private.ID)
+	if err != nil && !client.IsNotFound(err) {
+		addClientError(&resp.Diagnostics, path.Empty(), path.Empty(), "revoke token", err)
+		return
+	}
+}
+
+// tokenRenewAt returns when token should be renewed: shortly before it
+// expires, leaving time for the request to complete.
+func tokenRenewAt(token *client.Token) time.Time {
+	margin := min(time.Minute, time.Until(token.ExpiresAt)/4)
+	return token.ExpiresAt.Add(-margin)
+}
+
+// tokenTTLSeconds returns the configured ttl in seconds, or zero to use the
+// default, adding an attribute error unless it is a whole number of seconds
+// that NahCloud accepts.
+func tokenTTLSeconds(value types.String, diags *diag.Diagnostics) int {
+	if value.IsNull() {
+		return 0
+	}
+
+	var parseDiags diag.Diagnostics
+	ttl := parseDuration(path.Root("ttl"), value, &parseDiags)
+	diags.Append(parseDiags...)
+	if parseDiags.HasError() {
+		return 0
+	}
+	if ttl < minTokenTTL || ttl > maxTokenTTL || ttl%time.Second != 0 {
+		diags.AddAttributeError(
+			path.Root("ttl"),
+			"Invalid Token TTL",
+			fmt.Sprintf("ttl must be a whole number of seconds between 1m and 24h, got %q.", value.ValueString()),
+		)
+		return 0
+	}
+	return int(ttl / time.Second)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTokenEphemeralResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		TerraformVersionChecks:   testAccTerraformVersionChecks(tfversion.Version1_10_0),
+		ProtoV6ProviderFactories: testAccEchoProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTokenEphemeralResourceConfig(`["read"]`, "10m"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("id"), knownvalue.NotNull()),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("token"), knownvalue.StringRegexp(regexp.MustCompile(`^nah_`))),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("scopes"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.StringExact("read"),
+					})),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("expires_at"), knownvalue.NotNull()),
+				},
+			},
+			{
+				Config:      testAccTokenEphemeralResourceConfig(`["admin"]`, "10m"),
+				ExpectError: regexp.MustCompile(`admin`),
+			},
+			{
+				Config:      testAccTokenEphemeralResourceConfig(`["read"]`, "48h"),
+				ExpectError: regexp.MustCompile(`Invalid Token TTL`),
+			},
+			{
+				Config:      testAccTokenEphemeralResourceConfig(`["read"]`, "30s"),
+				ExpectError: regexp.MustCompile(`Invalid Token TTL`),
+			},
+		},
+	})
+}
+
+func TestTokenTTLSeconds(t *testing.T) {
+	tests := []struct {
+		ttl     types.String
+		want    int
+		wantErr bool
+	}{
+		{types.StringNull(), 0, false},
+		{types.StringValue("1m"), 60, false},
+		{types.StringValue("90m"), 5400, false},
+		{types.StringValue("24h"), 86400, false},
+		{types.StringValue("30s"), 0, true},
+		{types.StringValue("24h1s"), 0, true},
+		{types.StringValue("1m30.5s"), 0, true},
+		{types.StringValue("soon"), 0, true},
+	}
+	for _, tt := range tests {
+		var diags diag.Diagnostics
+		got := tokenTTLSeconds(tt.ttl, &diags)
+		if got != tt.want || diags.HasError() != tt.wantErr {
+			t.Errorf("tokenTTLSeconds(%s) = %d with diagnostics %v, want %d", tt.ttl, got, diags, tt.want)
+		}
+		for _, d := range diags {
+			if d, ok := d.(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("ttl")) {
+				t.Errorf("tokenTTLSeconds(%s): expected the error on ttl, got %v", tt.ttl, d)
+			}
+		}
+	}
+}
+
+func testAccTokenEphemeralResourceConfig(scopes, ttl string) string {
+	return fmt.Sprintf(`
+ephemeral "nah_token" "test" {
+  scopes = %s
+  ttl    = %q
+}
+
+provider "echo" {
+  data = ephemeral.nah_token.test
+}
+
+resource "echo" "test" {}
+`, scopes, ttl)
+}
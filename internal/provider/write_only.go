@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Some attributes hold secrets, and can also be set through a write-only
+// variant named <attr>_wo, which Terraform 1.11+ never stores in the plan or
+// state. As the provider cannot tell when a write-only value changes, it is
+// only sent when <attr>_wo_version changes.
+
+// validateWriteOnlyConfig checks that exactly one of attr and attr_wo is
+// configured, and that attr_wo_version is configured along with attr_wo.
+func validateWriteOnlyConfig(ctx context.Context, config tfsdk.Config, attr string, diags *diag.Diagnostics) {
+	var value, valueWO types.String
+	var version types.Int64
+
+	diags.Append(config.GetAttribute(ctx, path.Root(attr), &value)...)
+	diags.Append(config.GetAttribute(ctx, path.Root(attr+"_wo"), &valueWO)...)
+	diags.Append(config.GetAttribute(ctx, path.Root(attr+"_wo_version"), &version)...)
+	if diags.HasError() || value.IsUnknown() || valueWO.IsUnknown() || version.IsUnknown() {
+		return
+	}
+
+	switch {
+	case value.IsNull() && valueWO.IsNull():
+		diags.AddAttributeError(
+			path.Root(attr),
+			"Missing Attribute Configuration",
+			fmt.Sprintf("Exactly one of %q or %q must be configured.", attr, attr+"_wo"),
+		)
+	case !value.IsNull() && !valueWO.IsNull():
+		diags.AddAttributeError(
+			path.Root(attr+"_wo"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("Only one of %q or %q can be configured.", attr, attr+"_wo"),
+		)
+	case valueWO.IsNull() != version.IsNull():
+		diags.AddAttributeError(
+			path.Root(attr+"_wo_version"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("%q and %q must be configured together.", attr+"_wo", attr+"_wo_version"),
+		)
+	}
+}
+
+// writeOnlyValue returns the value of the write-only attribute attr_wo if it
+// must be sent, that is on create or when attr_wo_version changes. Write-only
+// values are always null in the plan, so it is read from config.
+func writeOnlyValue(ctx context.Context, config tfsdk.Config, attr string, version, priorVersion types.Int64, diags *diag.Diagnostics) *string {
+	if version.IsNull() || version.Equal(priorVersion) {
+		return nil
+	}
+
+	var valueWO types.String
+	diags.Append(config.GetAttribute(ctx, path.Root(attr+"_wo"), &valueWO)...)
+	if diags.HasError() {
+		return nil
+	}
+	return valueWO.ValueStringPointer()
+}